@@ -0,0 +1,131 @@
+package polyclip
+
+import "math"
+
+// Affine is a 2D affine transformation, mapping a point (x, y) to
+// (A*x + B*y + C, D*x + E*y + F).
+// The zero value is not a useful transformation; start from Identity.
+type Affine struct {
+	A, B, C float64
+	D, E, F float64
+}
+
+// Identity returns the transformation leaving all points in place.
+func Identity() Affine {
+	return Affine{A: 1, E: 1}
+}
+
+// Translate returns the transformation moving all points by (dx, dy).
+func Translate(dx, dy float64) Affine {
+	return Affine{A: 1, C: dx, E: 1, F: dy}
+}
+
+// Scale returns the transformation scaling all coordinates about the origin.
+// A negative factor reflects across an axis.
+func Scale(sx, sy float64) Affine {
+	return Affine{A: sx, E: sy}
+}
+
+// Rotate returns the transformation rotating all points about the origin,
+// counter-clockwise by the given angle.
+func Rotate(radians float64) Affine {
+	sin, cos := math.Sincos(radians)
+	return Affine{A: cos, B: -sin, D: sin, E: cos}
+}
+
+// Shear returns the transformation mapping (x, y) to (x + shx*y, y + shy*x).
+func Shear(shx, shy float64) Affine {
+	return Affine{A: 1, B: shx, D: shy, E: 1}
+}
+
+// Compose returns the transformation applying m first, and then n.
+// For example, Rotate(a).Compose(Translate(dx, dy)) rotates about the origin
+// before moving.
+func (m Affine) Compose(n Affine) Affine {
+	return Affine{
+		A: n.A*m.A + n.B*m.D,
+		B: n.A*m.B + n.B*m.E,
+		C: n.A*m.C + n.B*m.F + n.C,
+		D: n.D*m.A + n.E*m.D,
+		E: n.D*m.B + n.E*m.E,
+		F: n.D*m.C + n.E*m.F + n.F,
+	}
+}
+
+// Invert returns the transformation undoing m. It reports false if m collapses
+// the plane onto a line or point, and thus cannot be undone.
+func (m Affine) Invert() (Affine, bool) {
+	det := m.Det()
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		return Affine{}, false
+	}
+	return Affine{
+		A: m.E / det,
+		B: -m.B / det,
+		C: (m.B*m.F - m.E*m.C) / det,
+		D: -m.D / det,
+		E: m.A / det,
+		F: (m.D*m.C - m.A*m.F) / det,
+	}, true
+}
+
+// Det returns the determinant of the linear part of m, the factor by which it
+// scales areas. It is negative for transformations that include a reflection.
+func (m Affine) Det() float64 {
+	return m.A*m.E - m.B*m.D
+}
+
+// Transformed returns the image of p under m.
+func (p Point) Transformed(m Affine) Point {
+	return Point{m.A*p.X + m.B*p.Y + m.C, m.D*p.X + m.E*p.Y + m.F}
+}
+
+// Transform applies m to p in place.
+func (p *Point) Transform(m Affine) {
+	*p = p.Transformed(m)
+}
+
+// Transform applies m to all points of c in place. If m includes a reflection,
+// the order of the points is reversed so that the orientation of c is kept.
+func (c Contour) Transform(m Affine) {
+	for i := range c {
+		c[i].Transform(m)
+	}
+	if m.Det() < 0 {
+		for i, j := 0, len(c)-1; i < j; i, j = i+1, j-1 {
+			c[i], c[j] = c[j], c[i]
+		}
+	}
+}
+
+// Transformed returns a copy of c transformed by m; see Transform.
+func (c Contour) Transformed(m Affine) Contour {
+	r := c.Clone()
+	r.Transform(m)
+	return r
+}
+
+// Transform applies m to all contours of p in place; see Contour.Transform.
+func (p Polygon) Transform(m Affine) {
+	for _, c := range p {
+		c.Transform(m)
+	}
+}
+
+// Transformed returns a copy of p transformed by m; see Contour.Transform.
+func (p Polygon) Transformed(m Affine) Polygon {
+	r := p.Clone()
+	r.Transform(m)
+	return r
+}
+
+// Transformed returns the bounding box of the image of r under m, which is
+// r's image itself only if m does not rotate or shear.
+func (r Rectangle) Transformed(m Affine) Rectangle {
+	return Contour{r.Min, {r.Max.X, r.Min.Y}, r.Max, {r.Min.X, r.Max.Y}}.Transformed(m).BoundingBox()
+}
+
+// Transform replaces r with its Transformed bounding box in place.
+func (r *Rectangle) Transform(m Affine) {
+	*r = r.Transformed(m)
+}
@@ -0,0 +1,74 @@
+package polyclip
+
+import (
+	"math"
+	"testing"
+)
+
+func pointsCirca(a, b Point) bool {
+	return circa(a.X, b.X) && circa(a.Y, b.Y)
+}
+
+func TestAffine(t *testing.T) {
+	p := Point{1, 2}
+	cases := []struct {
+		name string
+		m    Affine
+		want Point
+	}{
+		{"identity", Identity(), Point{1, 2}},
+		{"translate", Translate(3, -1), Point{4, 1}},
+		{"scale", Scale(2, -3), Point{2, -6}},
+		{"rotate", Rotate(math.Pi / 2), Point{-2, 1}},
+		{"shear", Shear(1, 0), Point{3, 2}},
+		{"compose", Rotate(math.Pi / 2).Compose(Translate(1, 1)), Point{-1, 2}},
+		{"compose reversed", Translate(1, 1).Compose(Rotate(math.Pi / 2)), Point{-3, 2}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := p.Transformed(c.m)
+			verify(t, pointsCirca(got, c.want), "expected %v, got %v", c.want, got)
+			inv, ok := c.m.Invert()
+			verify(t, ok, "expected %v to be invertible", c.m)
+			verify(t, pointsCirca(got.Transformed(inv), p), "expected inverse to map %v back to %v, got %v", got, p, got.Transformed(inv))
+		})
+	}
+
+	_, ok := Scale(1, 0).Invert()
+	verify(t, !ok, "expected degenerate transformation not to be invertible")
+
+	q := p
+	q.Transform(Translate(1, 1))
+	verify(t, q.Equals(Point{2, 3}) && p.Equals(Point{1, 2}), "expected in-place transformation of a copy only, got %v %v", p, q)
+}
+
+func TestAffineContour(t *testing.T) {
+	// Counter-clockwise contours have positive signed area.
+	signedArea := func(c Contour) float64 {
+		a := 0.
+		for i := range c {
+			s := c.segment(i)
+			a += s.start.X*s.end.Y - s.end.X*s.start.Y
+		}
+		return a / 2
+	}
+	c := Contour{{0, 0}, {2, 0}, {2, 1}, {0, 1}}
+	for _, m := range []Affine{Scale(-1, 1), Scale(1, -1), Scale(-2, -2), Rotate(1).Compose(Scale(1, -1)), Shear(0.5, 0)} {
+		r := c.Transformed(m)
+		verify(t, circa(signedArea(r), signedArea(c)*math.Abs(m.Det())),
+			"%v: expected area %g with orientation kept, got %g", m, signedArea(c)*math.Abs(m.Det()), signedArea(r))
+	}
+	verify(t, c[1].Equals(Point{2, 0}), "expected Transformed not to modify the original, got %v", c)
+
+	p := Polygon{c.Clone(), {{0.5, 0.25}, {0.5, 0.75}, {1, 0.5}}}
+	p.Transform(Scale(-1, 1))
+	verify(t, signedArea(p[0]) > 0 && signedArea(p[1]) < 0, "expected orientations kept in place, got %v", p)
+}
+
+func TestAffineRectangle(t *testing.T) {
+	r := Rectangle{Point{0, 0}, Point{2, 1}}
+	got := r.Transformed(Rotate(math.Pi / 2))
+	verify(t, pointsCirca(got.Min, Point{-1, 0}) && pointsCirca(got.Max, Point{0, 2}), "expected bounding box of rotated rectangle, got %v", got)
+	r.Transform(Scale(-1, 2))
+	verify(t, r == Rectangle{Point{-2, 0}, Point{0, 2}}, "expected reflected rectangle, got %v", r)
+}
@@ -500,8 +500,8 @@ func TestNonReductiveSegmentDivisions(t *testing.T) {
 		// Test multiple rotations of each case to catch any orientation assumptions.
 		for i := 0; i < rotations; i++ {
 			angle := 2 * math.Pi * float64(i) / float64(rotations)
-			subject := rotate(c.subject, angle)
-			clipping := rotate(c.clipping, angle)
+			subject := c.subject.Transformed(polyclip.Rotate(angle))
+			clipping := c.clipping.Transformed(polyclip.Rotate(angle))
 
 			for _, op := range []polyclip.Op{polyclip.UNION, polyclip.INTERSECTION, polyclip.DIFFERENCE} {
 				ch := make(chan polyclip.Polygon)
@@ -521,20 +521,6 @@ func TestNonReductiveSegmentDivisions(t *testing.T) {
 	}
 }
 
-func rotate(p polyclip.Polygon, radians float64) polyclip.Polygon {
-	result := p.Clone()
-	for i, contour := range p {
-		result[i] = make(polyclip.Contour, len(contour))
-		for j, point := range contour {
-			result[i][j] = polyclip.Point{
-				X: point.X*math.Cos(radians) - point.Y*math.Sin(radians),
-				Y: point.Y*math.Cos(radians) + point.X*math.Sin(radians),
-			}
-		}
-	}
-	return result
-}
-
 func TestBug5(t *testing.T) {
 	rect := polyclip.Polygon{{{24, 7}, {36, 7}, {36, 23}, {24, 23}}}
 	circle := polyclip.Polygon{{{24, 7}, {24.83622770614123, 7.043824837053814}, {25.66329352654208, 7.174819194129555}, {26.472135954999587, 7.391547869638773}, {27.253893144606412, 7.691636338859195}, {28.00000000000001, 8.071796769724493}, {28.702282018339798, 8.527864045000424}, {29.35304485087088, 9.054841396180851}, {29.94515860381917, 9.646955149129141}, {30.472135954999597, 10.297717981660224}, {30.92820323027553, 11.00000000000001}, {31.308363661140827, 11.746106855393611}, {31.60845213036125, 12.527864045000435}, {31.825180805870467, 13.33670647345794}, {31.95617516294621, 14.16377229385879}, {32.00000000000002, 15.00000000000002}, {31.95617516294621, 15.83622770614125}, {31.825180805870467, 16.6632935265421}, {31.60845213036125, 17.472135954999604}, {31.308363661140827, 18.25389314460643}, {30.92820323027553, 19.00000000000003}, {30.472135954999597, 19.702282018339815}, {29.94515860381917, 20.353044850870898}, {29.35304485087088, 20.945158603819188}, {28.702282018339798, 21.472135954999615}, {28.00000000000001, 21.928203230275546}, {27.253893144606412, 22.308363661140845}, {26.472135954999587, 22.608452130361268}, {25.66329352654208, 22.825180805870485}, {24.83622770614123, 22.956175162946227}, {24, 23.00000000000004}, {23.16377229385877, 22.956175162946227}, {22.33670647345792, 22.825180805870485}, {21.527864045000413, 22.608452130361268}, {20.746106855393588, 22.308363661140845}, {19.99999999999999, 21.928203230275546}, {19.297717981660202, 21.472135954999615}, {18.64695514912912, 20.945158603819188}, {18.05484139618083, 20.353044850870898}, {17.527864045000403, 19.702282018339815}, {17.07179676972447, 19.00000000000003}, {16.691636338859173, 18.25389314460643}, {16.39154786963875, 17.472135954999604}, {16.174819194129533, 16.6632935265421}, {16.04382483705379, 15.83622770614125}, {15.999999999999977, 15.00000000000002}, {16.04382483705379, 14.16377229385879}, {16.174819194129533, 13.33670647345794}, {16.39154786963875, 12.527864045000435}, {16.691636338859173, 11.746106855393611}, {17.07179676972447, 11.00000000000001}, {17.527864045000403, 10.297717981660224}, {18.05484139618083, 9.646955149129141}, {18.64695514912912, 9.054841396180851}, {19.297717981660202, 8.527864045000424}, {19.99999999999999, 8.071796769724493}, {20.746106855393588, 7.691636338859194}, {21.527864045000413, 7.391547869638772}, {22.33670647345792, 7.1748191941295545}, {23.16377229385877, 7.043824837053813}}}
@@ -640,13 +626,57 @@ func TestInfiniteLoopBug(t *testing.T) {
 		polyclip.Point{X: 426745.3964821229, Y: -668550.4652243527},
 	}}
 
-	want := polyclip.Polygon{polyclip.Contour{
-		{426694.6365274183, -668547.1611580737}, {426731.5895193888, -668549.5664294426},
-		{426745.39627072256, -668550.4651113059}, {426745.3962772624, -668550.4651148032},
-		{426714.57523030025, -668548.9238652373},
-	}}
+	// The difference has two pieces, as ConstructExact also finds. Before the
+	// operands were translated towards the origin, the sweep missed the
+	// crossing next to the subject's second vertex, and returned a single
+	// contour joining both pieces that left out the subject's last vertex.
+	want := polyclip.Polygon{
+		polyclip.Contour{
+			{426745.39627072256, -668550.4651113059}, {426745.39648089616, -668550.4651249861},
+			{426745.3962772624, -668550.4651148032},
+		},
+		polyclip.Contour{
+			{426694.6365274183, -668547.1611580737}, {426731.5895193888, -668549.5664294426},
+			{426714.57523030386, -668548.9238652375}, {426714.57523030025, -668548.9238652373},
+		},
+	}
 	result := subject.Construct(polyclip.DIFFERENCE, clipping)
 	if dump(want) != dump(result) {
 		t.Errorf("expected:\n%v\ngot:\n%v", dump(want), dump(result))
 	}
 }
+
+// The triangle touches the bottom edge of the square from inside with its
+// left endpoint. The sweep took the triangle's lower edge, starting on the
+// bottom edge, as lying below it, and so outside the square.
+func TestLeftEndpointOnEdge(t *testing.T) {
+	square := polyclip.Polygon{{{0, 4}, {0, 0}, {4, 0}, {4, 4}}}
+	triangle := polyclip.Polygon{{{1, 1}, {1, 0}, {3, 3}}}
+	testCases{
+		{polyclip.UNION, square, triangle, polyclip.Polygon{{{0, 4}, {0, 0}, {1, 0}, {4, 0}, {4, 4}}}},
+		{polyclip.INTERSECTION, square, triangle, triangle},
+		{polyclip.DIFFERENCE, square, triangle, polyclip.Polygon{{{0, 4}, {0, 0}, {1, 0}, {1, 1}, {3, 3}, {1, 0}, {4, 0}, {4, 4}}}},
+	}.verify(t)
+}
+
+// The line crosses the left edge of the square at an angle too small for the
+// lines of both to be told apart in floating point. The crossing used to be
+// missed, keeping the part of the line above the square.
+func TestClipLineNearlyParallel(t *testing.T) {
+	e := math.Nextafter(1, 2) - 1
+	line := polyclip.Polygon{{{0.5, -1}, {1 - e, 0}, {1 + e, 1000}, {1.5, 1001}}}
+	square := polyclip.Polygon{{{1, 0}, {2, 0}, {2, 1000}, {1, 1000}}}
+	testCases{
+		{polyclip.CLIPLINE, line, square, polyclip.Polygon{{{1, 1000}, {1, 500}}}},
+	}.verify(t)
+}
+
+func TestXORTrivialCases(t *testing.T) {
+	square := polyclip.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}
+	far := polyclip.Polygon{{{5, 5}, {6, 5}, {6, 6}, {5, 6}}}
+	testCases{
+		{polyclip.XOR, square, polyclip.Polygon{}, square},
+		{polyclip.XOR, polyclip.Polygon{}, far, far},
+		{polyclip.XOR, square, far, polyclip.Polygon{square[0], far[0]}},
+	}.verify(t)
+}
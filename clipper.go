@@ -23,13 +23,7 @@
 
 package polyclip
 
-import (
-	"fmt"
-	"math"
-)
-
-//func _DBG(f func()) { f() }
-func _DBG(f func()) {}
+import "math"
 
 type polygonType int
 
@@ -53,22 +47,40 @@ const (
 type clipper struct {
 	subject, clipping Polygon
 	eventQueue
+
+	provenance    *Provenance // if not nil, filled with the origins of the result's edges
+	conditioning  Conditioning
+	cond          *conditioner // if not nil, maps the operands moved before the sweep back
+	linkTolerance float64
+	diagnostics   *Diagnostics // if not nil, filled with the places where geometry was lost
+	stats         *Stats       // if not nil, filled with statistics about the operation
+	tracer        Tracer       // if not nil, passed the steps of the sweep
+	traced        *connector   // the connector whose chains are passed to a StateTracer
+	recovered     *error       // if not nil, panics are recovered into it
+	reproducerDir string       // if not empty, reproducers of recovered panics are written there
 }
 
-func (c *clipper) compute(operation Op) Polygon {
+func (c *clipper) compute(operation Op) (result Polygon) {
+	defer c.recoverPanic(false, operation, c.subject, c.clipping, &result)
+	if c.diagnostics != nil {
+		*c.diagnostics = Diagnostics{}
+	}
+	if c.stats != nil {
+		*c.stats = Stats{}
+	}
 
 	// Test 1 for trivial result case
 	if len(c.subject)*len(c.clipping) == 0 {
 		switch operation {
 		case DIFFERENCE:
-			return c.subject.Clone()
-		case UNION:
+			return c.copyOf(c.subject, nil)
+		case UNION, XOR:
 			if len(c.subject) == 0 {
-				return c.clipping.Clone()
+				return c.copyOf(nil, c.clipping)
 			}
-			return c.subject.Clone()
+			return c.copyOf(c.subject, nil)
 		}
-		return Polygon{}
+		return c.copyOf(nil, nil)
 	}
 
 	// Test 2 for trivial result case
@@ -77,63 +89,45 @@ func (c *clipper) compute(operation Op) Polygon {
 	if !subjectbb.Overlaps(clippingbb) {
 		switch operation {
 		case DIFFERENCE:
-			return c.subject.Clone()
-		case UNION:
-			result := c.subject.Clone()
-			for _, cont := range c.clipping {
-				result.Add(cont.Clone())
-			}
-			return result
+			return c.copyOf(c.subject, nil)
+		case UNION, XOR:
+			return c.copyOf(c.subject, c.clipping)
 		}
-		return Polygon{}
+		return c.copyOf(nil, nil)
 	}
 
-	// Add each segment to the eventQueue, sorted from left to right.
-	for _, cont := range c.subject {
-		for i := range cont {
-			if !(operation == CLIPLINE && i == len(cont)-1) {
-				// Add subject segment to event queue, unless the subject is a line
-				// string and it is the last (closing) segment.
-				addProcessedSegment(&c.eventQueue, cont.segment(i), _SUBJECT)
-			}
-		}
-	}
-	for _, cont := range c.clipping {
-		for i := range cont {
-			addProcessedSegment(&c.eventQueue, cont.segment(i), _CLIPPING)
-		}
+	start := c.stats.start()
+
+	// Move operands far from the origin closer to it.
+	operands := c.condition(c.subject, c.clipping)
+	if c.cond != nil {
+		c.subject, c.clipping = operands[0], operands[1]
+		subjectbb, clippingbb = c.subject.BoundingBox(), c.clipping.BoundingBox()
 	}
 
-	connector := connector{operation: operation} // to connect the edge solutions
+	// Add each segment to the eventQueue, sorted from left to right.
+	c.enqueueOperands(operation == CLIPLINE)
+	if c.stats != nil {
+		c.stats.InputSegments = len(c.eventQueue.elements) / 2
+	}
+	start = c.stats.lap(statSetup, start)
 
-	// This is the sweepline. That is, we go through all the polygon edges
-	// by sweeping from left to right.
-	S := sweepline{}
+	connector := connector{operation: operation, stats: c.stats, tracer: c.tracer} // to connect the edge solutions
+	connector.epsilon, connector.repairGap = c.linkTolerance, c.linkTolerance
+	if c.provenance != nil {
+		connector.origins = make(map[segment][]EdgeOrigin)
+	}
 
 	MINMAX_X := math.Min(subjectbb.Max.X, clippingbb.Max.X)
 
-	_DBG(func() {
-		e := c.eventQueue.dequeue()
-		c.eventQueue.enqueue(e)
-		fmt.Print("\nInitial queue:\n")
-		for i, e := range c.eventQueue.elements {
-			fmt.Println(i, "=", *e)
-		}
-	})
-
-	for !c.eventQueue.IsEmpty() {
-		var prev, next *endpoint
-		e := c.eventQueue.dequeue()
-		_DBG(func() { fmt.Printf("\nProcess event: (of %d)\n%v\n", len(c.eventQueue.elements)+1, *e) })
-
+	done := func(e *endpoint) bool {
 		// optimization 1
 		switch {
 		case (operation == INTERSECTION || operation == CLIPLINE) && e.p.X > MINMAX_X:
-			fallthrough
+			return true
 		case operation == DIFFERENCE && e.p.X > subjectbb.Max.X:
-			return connector.toPolygon()
+			return true
 			//case operation == UNION && e.p.X > MINMAX_X:
-			//	_DBG(func() { fmt.Print("\nUNION optimization, fast quit\n") })
 			//	// add all the non-processed line segments to the result
 			//	if !e.left {
 			//		connector.add(e.segment())
@@ -147,9 +141,65 @@ func (c *clipper) compute(operation Op) Polygon {
 			//	}
 			//	return connector.toPolygon()
 		}
+		return false
+	}
+	emit := func(e *endpoint) {
+		// Check if the line segment belongs to the Boolean operation
+		if contributes(operation, e) {
+			c.trace(TraceEvent{Kind: TRACE_EMITTED, Point: e.p}, e)
+			connector.addEdge(e)
+		}
+	}
+	c.traced = &connector
+	c.traceOperands(c.subject, c.clipping)
+	c.sweep(done, emit)
+	start = c.stats.lap(statSweep, start)
+	result = c.finish(&connector)
+	c.revert(result)
+	c.stats.lap(statConnect, start)
+	return result
+}
+
+// enqueueOperands adds the segments of both polygons to the event queue.
+// If subjectIsLine is set, the subject contours are treated as line strings,
+// leaving out their closing segments.
+func (c *clipper) enqueueOperands(subjectIsLine bool) {
+	for j, cont := range c.subject {
+		for i := range cont {
+			if !(subjectIsLine && i == len(cont)-1) {
+				addProcessedSegment(&c.eventQueue, cont.segment(i), _SUBJECT, j, i)
+			}
+		}
+	}
+	for j, cont := range c.clipping {
+		for i := range cont {
+			addProcessedSegment(&c.eventQueue, cont.segment(i), _CLIPPING, j, i)
+		}
+	}
+}
+
+// sweep goes through all the queued polygon edges from left to right, dividing
+// them where they intersect and computing their inside and inout flags.
+// Each segment is passed to emit when its right endpoint is processed.
+// The sweep stops early when done returns true for the next event.
+func (c *clipper) sweep(done func(e *endpoint) bool, emit func(e *endpoint)) {
+	// This is the sweepline. That is, we go through all the polygon edges
+	// by sweeping from left to right.
+	S := sweepline{}
+
+	for !c.eventQueue.IsEmpty() {
+		var prev, next *endpoint
+		e := c.eventQueue.dequeue()
+		c.stats.event(len(c.eventQueue.elements))
+		c.trace(TraceEvent{Kind: TRACE_DEQUEUED, Point: e.p, Left: e.left}, e)
+
+		if done(e) {
+			return
+		}
 
 		if e.left { // the line segment must be inserted into S
 			pos := S.insert(e)
+			c.stats.sweepLine(len(S))
 			//e.PosInS = pos
 
 			prev = nil
@@ -193,12 +243,7 @@ func (c *clipper) compute(operation Op) Polygon {
 				e.inout = prev.inside
 			}
 
-			_DBG(func() {
-				fmt.Println("Status line after insertion: ")
-				for _, e := range S {
-					fmt.Println(*e)
-				}
-			})
+			c.traceInserted(e, prev, next)
 
 			// Process a possible intersection between "e" and its next neighbor in S
 			if next != nil {
@@ -216,6 +261,7 @@ func (c *clipper) compute(operation Op) Polygon {
 				if len(divided) == 1 && divided[0] == prev {
 					S.remove(e)
 					c.eventQueue.enqueue(e)
+					c.stats.count(statReenqueued)
 				}
 			}
 		} else { // the line segment must be removed from S
@@ -240,40 +286,7 @@ func (c *clipper) compute(operation Op) Polygon {
 				}
 			}
 
-			// Check if the line segment belongs to the Boolean operation
-			if operation == CLIPLINE {
-				if e.other.inside && e.polygonType == _SUBJECT {
-					connector.add(e.segment())
-				}
-			}
-			switch e.edgeType {
-			case _EDGE_NORMAL:
-				switch operation {
-				case INTERSECTION:
-					if e.other.inside {
-						connector.add(e.segment())
-					}
-				case UNION:
-					if !e.other.inside {
-						connector.add(e.segment())
-					}
-				case DIFFERENCE:
-					if (e.polygonType == _SUBJECT && !e.other.inside) ||
-						(e.polygonType == _CLIPPING && e.other.inside) {
-						connector.add(e.segment())
-					}
-				case XOR:
-					connector.add(e.segment())
-				}
-			case _EDGE_SAME_TRANSITION:
-				if operation == INTERSECTION || operation == UNION {
-					connector.add(e.segment())
-				}
-			case _EDGE_DIFFERENT_TRANSITION:
-				if operation == DIFFERENCE {
-					connector.add(e.segment())
-				}
-			}
+			emit(e)
 
 			// delete line segment associated to e from S and check for intersection between the neighbors of "e" in S
 			if otherPos != -1 {
@@ -283,17 +296,76 @@ func (c *clipper) compute(operation Op) Polygon {
 			if next != nil && prev != nil {
 				c.possibleIntersection(next, prev)
 			}
+		}
+		c.traceState(e.p, S, c.traced)
+	}
+}
 
-			_DBG(func() { fmt.Print("Connector:\n", connector, "\n") })
+// contributes returns whether the segment associated with e, a right endpoint,
+// is part of the result of operation.
+func contributes(operation Op, e *endpoint) bool {
+	if operation == CLIPLINE {
+		return e.other.inside && e.polygonType == _SUBJECT
+	}
+	switch e.edgeType {
+	case _EDGE_NORMAL:
+		switch operation {
+		case INTERSECTION:
+			return e.other.inside
+		case UNION:
+			return !e.other.inside
+		case DIFFERENCE:
+			return isDifferenceEdge(e, _SUBJECT)
+		case XOR:
+			return true
 		}
-		_DBG(func() {
-			fmt.Println("Status line after processing intersections: ")
-			for _, e := range S {
-				fmt.Println(*e)
+	case _EDGE_SAME_TRANSITION:
+		return operation == INTERSECTION || operation == UNION
+	case _EDGE_DIFFERENT_TRANSITION:
+		return operation == DIFFERENCE
+	}
+	return false
+}
+
+// isDifferenceEdge returns whether the normal segment associated with e bounds
+// the difference of the polygon of type minuend minus the other polygon.
+func isDifferenceEdge(e *endpoint, minuend polygonType) bool {
+	return (e.polygonType == minuend) != e.other.inside
+}
+
+// finish converts the chains of the connector into the result polygon.
+func (c *clipper) finish(conn *connector) Polygon {
+	poly := conn.toPolygon()
+	if c.diagnostics != nil && conn.operation != CLIPLINE {
+		for _, ch := range conn.openPolys {
+			c.diagnostics.UnclosedChains = append(c.diagnostics.UnclosedChains, Contour(ch.points).Clone())
+		}
+	}
+	if c.provenance != nil {
+		*c.provenance = conn.provenance(poly)
+	}
+	return poly
+}
+
+// copyOf returns a copy of all contours of subject followed by all contours of
+// clipping, for the trivial cases where no sweep is needed.
+func (c *clipper) copyOf(subject, clipping Polygon) Polygon {
+	result := Polygon{}
+	var prov Provenance
+	for src, poly := range []Polygon{subject, clipping} {
+		for i, cont := range poly {
+			result.Add(cont.Clone())
+			origins := make([]EdgeOrigin, len(cont))
+			for j := range origins {
+				origins[j] = EdgeOrigin{Source: Source(src), Contour: i, Edge: j}
 			}
-		})
+			prov = append(prov, origins)
+		}
 	}
-	return connector.toPolygon()
+	if c.provenance != nil {
+		*c.provenance = prov
+	}
+	return result
 }
 
 func findIntersection(seg0, seg1 segment, tryBothDirections bool) (int, Point, Point) {
@@ -305,11 +377,16 @@ func findIntersection(seg0, seg1 segment, tryBothDirections bool) (int, Point, P
 	sqrEpsilon := 1e-15 // was originally 1e-3, which is very prone to false positives
 	E := Point{p1.X - p0.X, p1.Y - p0.Y}
 	kross := d0.X*d1.Y - d0.Y*d1.X
-	sqrKross := kross * kross
-	sqrLen0 := d0.Length()
-	sqrLen1 := d1.Length()
-
-	if sqrKross > sqrEpsilon*sqrLen0*sqrLen1 {
+	len0 := d0.Length()
+	len1 := d1.Length()
+
+	// kross is the product of the segments' lengths and the sine of the angle
+	// between them. Comparing the sine rather than kross itself keeps short
+	// segments, such as the pieces of divided ones, from being taken as
+	// parallel to any long segment they nearly align with. Neither side is
+	// squared, which for segments of lengths below about 1e-80 would underflow.
+	const sinEpsilon = 1e-10
+	if math.Abs(kross) > sinEpsilon*len0*len1 {
 		// lines of the segments are not parallel
 		s := (E.X*d1.Y - E.Y*d1.X) / kross
 		if s < 0 || s > 1 {
@@ -320,53 +397,121 @@ func findIntersection(seg0, seg1 segment, tryBothDirections bool) (int, Point, P
 			return 0, Point{}, Point{}
 		}
 		// intersection of lines is a point an each segment [MC: ?]
-		pi0.X = p0.X + s*d0.X
-		pi0.Y = p0.Y + s*d0.Y
-
-		// [MC: commented fragment removed]
-
-		return 1, pi0, pi1
+		return 1, crossingPoint(seg0, seg1, s), pi1
 	}
 
-	// lines of the segments are parallel
-	sqrLenE := E.Length()
+	// Lines of the segments are parallel, or at too small an angle to tell.
+	// Segments at such an angle may still cross, such as long nearly vertical
+	// segments with ends a few ulps apart, which must not be taken as
+	// overlapping: their order along the line is not that of their x
+	// coordinates, which dividing overlapping segments relies on.
+	if strictlyCross(seg0, seg1) {
+		return 1, crossingPoint(seg0, seg1, 0.5), pi1
+	}
+	// Likewise, the lines are the same if the sine of the angle between seg0
+	// and the line from its start to that of seg1 is small, whatever their
+	// lengths, rather than the lines being less than about 3e-8 apart.
+	lenE := E.Length()
 	kross = E.X*d0.Y - E.Y*d0.X
-	sqrKross = kross * kross
-	if sqrKross > sqrEpsilon*sqrLen0*sqrLenE {
+	if math.Abs(kross) > math.Sqrt(sqrEpsilon)*len0*lenE {
 		// lines of the segment are different
 		return 0, pi0, pi1
 	}
 
 	// Lines of the segment are the same. Need to test for overlap of segments.
-	// s0 = Dot (D0, E) * sqrLen0
+	// s0 and s1 are the positions of the ends of seg1 along seg0, as fractions
+	// of its length: s0 = Dot(D0, E) / Dot(D0, D0)
+	sqrLen0 := d0.X*d0.X + d0.Y*d0.Y
 	s0 := (d0.X*E.X + d0.Y*E.Y) / sqrLen0
-	// s1 = s0 + Dot (D0, D1) * sqrLen0
+	// s1 = s0 + Dot(D0, D1) / Dot(D0, D0)
 	s1 := s0 + (d0.X*d1.X+d0.Y*d1.Y)/sqrLen0
 	smin := math.Min(s0, s1)
 	smax := math.Max(s0, s1)
 	w := make([]float64, 0)
 	imax := findIntersection2(0.0, 1.0, smin, smax, &w)
 
+	// The ends of the overlap are ends of the segments; return those rather
+	// than points computed from their positions.
+	at := func(w float64) Point {
+		switch w {
+		case 0:
+			return p0
+		case 1:
+			return seg0.end
+		case s0:
+			return p1
+		case s1:
+			return seg1.end
+		}
+		return Point{p0.X + w*d0.X, p0.Y + w*d0.Y}
+	}
 	if imax > 0 {
-		pi0.X = p0.X + w[0]*d0.X
-		pi0.Y = p0.Y + w[0]*d0.Y
+		pi0 = at(w[0])
 	}
 	// For same-segment scenarios, this should be the case.
 	if imax > 1 {
-		pi1.X = p0.X + w[1]*d0.X
-		pi1.Y = p0.Y + w[1]*d0.Y
-
+		pi1 = at(w[1])
 	} else if tryBothDirections {
 		// However, findIntersection() is not symmetric and sometimes fails in one direction. Try the other.
 		if otherImax, otherPi0, otherPi1 := findIntersection(seg1, seg0, false); otherImax > imax {
 			return otherImax, otherPi0, otherPi1
 		}
-		_DBG(func() { fmt.Printf("WARNING: Could not find overlap in segments %v, %v\n", seg0, seg1) })
 	}
 
 	return imax, pi0, pi1
 }
 
+// strictlyCross returns whether the ends of each of seg0 and seg1 lie on
+// opposite sides of the line through the other.
+func strictlyCross(seg0, seg1 segment) bool {
+	return ddOrientation(seg0.start, seg0.end, seg1.start)*ddOrientation(seg0.start, seg0.end, seg1.end) < 0 &&
+		ddOrientation(seg1.start, seg1.end, seg0.start)*ddOrientation(seg1.start, seg1.end, seg0.end) < 0
+}
+
+// crossingPoint returns the point where the lines through the non-parallel
+// segments seg0 and seg1 cross. It is computed in double-double arithmetic,
+// from the exact differences of the coordinates, so that even for long, nearly
+// parallel segments it is off from both by little more than the final rounding.
+// The result is clamped to the bounding boxes of both segments.
+// The plain float64 estimate s of the position along seg0 is used instead if
+// double-double arithmetic fails because of underflow.
+func crossingPoint(seg0, seg1 segment, s0 float64) Point {
+	d0x, d0y := ddDiff(seg0.end.X, seg0.start.X), ddDiff(seg0.end.Y, seg0.start.Y)
+	d1x, d1y := ddDiff(seg1.end.X, seg1.start.X), ddDiff(seg1.end.Y, seg1.start.Y)
+	ex, ey := ddDiff(seg1.start.X, seg0.start.X), ddDiff(seg1.start.Y, seg0.start.Y)
+	kross := d0x.mul(d1y).sub(d0y.mul(d1x))
+	s := ex.mul(d1y).sub(ey.mul(d1x)).div(kross)
+
+	p := Point{
+		ddouble{seg0.start.X, 0}.add(s.mul(d0x)).hi,
+		ddouble{seg0.start.Y, 0}.add(s.mul(d0y)).hi,
+	}
+	if math.IsNaN(p.X) || math.IsNaN(p.Y) || math.IsInf(p.X, 0) || math.IsInf(p.Y, 0) {
+		p = Point{
+			seg0.start.X + s0*(seg0.end.X-seg0.start.X),
+			seg0.start.Y + s0*(seg0.end.Y-seg0.start.Y),
+		}
+	}
+	return clampToBoxes(p, seg0, seg1)
+}
+
+// clampToBoxes moves p into the intersection of the bounding boxes of seg0 and
+// seg1, if it is not empty.
+func clampToBoxes(p Point, seg0, seg1 segment) Point {
+	b0, b1 := seg0.bounds(), seg1.bounds()
+	clamp := func(v, lo0, hi0, lo1, hi1 float64) float64 {
+		lo, hi := math.Max(lo0, lo1), math.Min(hi0, hi1)
+		if lo > hi {
+			return v
+		}
+		return math.Max(lo, math.Min(hi, v))
+	}
+	return Point{
+		clamp(p.X, b0.Min.X, b0.Max.X, b1.Min.X, b1.Max.X),
+		clamp(p.Y, b0.Min.Y, b0.Max.Y, b1.Min.Y, b1.Max.Y),
+	}
+}
+
 func findIntersection2(u0, u1, v0, v1 float64, w *[]float64) int {
 	if u1 < v0 || u0 > v1 {
 		return 0
@@ -423,12 +568,22 @@ func (c *clipper) possibleIntersection(e1, e2 *endpoint) []*endpoint {
 	ip1 = snap(ip1, e1.p, e2.p, e1.other.p, e2.other.p)
 
 	if numIntersections == 1 {
+		touching := e1.p.Equals(e2.p) || e1.other.p.Equals(e2.other.p)
+		valid := touching || isValidSingleIntersection(e1, e2, ip1)
+		c.trace(TraceEvent{Kind: TRACE_INTERSECTION, Point: ip1, Count: 1, Dropped: !valid}, e1, e2)
 		switch {
-		case e1.p.Equals(e2.p) || e1.other.p.Equals(e2.other.p):
+		case touching:
+			c.stats.count(statTouch)
 			return nil // the line segments intersect at an endpoint of both line segments
-		case !isValidSingleIntersection(e1, e2, ip1):
-			_DBG(func() { fmt.Printf("Dropping invalid intersection %v between %v and %v\n", ip1, e1, e2) })
+		case !valid:
+			c.stats.count(statDroppedIntersection)
+			if c.diagnostics != nil {
+				c.diagnostics.DroppedIntersections = append(c.diagnostics.DroppedIntersections, newDroppedDivision(ip1, e1, e2))
+			}
 			return nil
+		}
+		c.stats.count(statCrossing)
+		switch {
 		case e1.p.Equals(ip1) || e1.other.p.Equals(ip1): // e1 divides e2
 			return []*endpoint{c.divideSegment(e2, ip1)}
 		case e2.p.Equals(ip1) || e2.other.p.Equals(ip1): // e2 divides e1
@@ -441,6 +596,8 @@ func (c *clipper) possibleIntersection(e1, e2 *endpoint) []*endpoint {
 		}
 	}
 
+	c.stats.count(statOverlap)
+	c.trace(TraceEvent{Kind: TRACE_INTERSECTION, Point: ip1, Count: numIntersections}, e1, e2)
 	if numIntersections == 2 && e1.polygonType == e2.polygonType {
 		return nil // the line segments overlap, but they belong to the same polygon
 	}
@@ -524,13 +681,17 @@ func (c *clipper) possibleIntersection(e1, e2 *endpoint) []*endpoint {
 // Returns the original endpoint if successfully divided, otherwise nil.
 func (c *clipper) divideSegment(e *endpoint, p Point) *endpoint {
 	// "Right event" of the "left line segment" resulting from dividing e (the line segment associated to e)
-	r := &endpoint{p: p, left: false, polygonType: e.polygonType, other: e, edgeType: e.edgeType}
+	r := &endpoint{p: p, left: false, polygonType: e.polygonType, other: e, edgeType: e.edgeType, contour: e.contour, edge: e.edge}
 	// "Left event" of the "right line segment" resulting from dividing e (the line segment associated to e)
-	l := &endpoint{p: p, left: true, polygonType: e.polygonType, other: e.other, edgeType: e.other.edgeType}
+	l := &endpoint{p: p, left: true, polygonType: e.polygonType, other: e.other, edgeType: e.other.edgeType, contour: e.contour, edge: e.edge}
 
 	// Discard segments of the wrong-direction (including zero-length). See isValidSingleIntersection() for reasoning.
 	if !l.isValidDirection() || !r.isValidDirection() {
-		_DBG(func() { fmt.Printf("Dropping invalid division of %v at %v:\n - %v\n - %v\n", *e, p, l, r) })
+		c.trace(TraceEvent{Kind: TRACE_DIVIDED, Point: p, Dropped: true}, e)
+		c.stats.count(statDroppedDivision)
+		if c.diagnostics != nil {
+			c.diagnostics.DroppedDivisions = append(c.diagnostics.DroppedDivisions, newDroppedDivision(p, e))
+		}
 		return nil
 	}
 
@@ -541,20 +702,25 @@ func (c *clipper) divideSegment(e *endpoint, p Point) *endpoint {
 
 	e.other.other = l
 	e.other = r
+	c.stats.count(statDivision)
+	c.trace(TraceEvent{Kind: TRACE_DIVIDED, Point: p}, e)
 
 	c.eventQueue.enqueue(l)
 	c.eventQueue.enqueue(r)
 	return e
 }
 
-func addProcessedSegment(q *eventQueue, segment segment, polyType polygonType) {
+// addProcessedSegment enqueues both endpoints of a segment. The contour and edge
+// arguments locate the segment within its input and are carried over to all the
+// segments it is divided into.
+func addProcessedSegment(q *eventQueue, segment segment, polyType polygonType, contour, edge int) {
 	if segment.start.Equals(segment.end) {
 		// Possible degenerate condition
 		return
 	}
 
-	e1 := &endpoint{p: segment.start, left: true, polygonType: polyType}
-	e2 := &endpoint{p: segment.end, left: true, polygonType: polyType, other: e1}
+	e1 := &endpoint{p: segment.start, left: true, polygonType: polyType, contour: contour, edge: edge}
+	e2 := &endpoint{p: segment.end, left: true, polygonType: polyType, other: e1, contour: contour, edge: edge}
 	e1.other = e2
 
 	switch {
@@ -0,0 +1,70 @@
+package polyclip
+
+// Collection holds a result of mixed dimension: polygons, line strings and points.
+type Collection struct {
+	Polygon Polygon
+	Lines   []Contour // Open line strings; closed ones repeat their first point at the end
+	Points  []Point
+}
+
+// IntersectAll computes the intersection of p and clipping, like Construct with
+// INTERSECTION, but also keeps the lower-dimensional parts of the intersection:
+// the lines along which the boundaries of the polygons meet with their interiors
+// on opposite sides, and the isolated points where the boundaries touch.
+// For example, the intersection of two squares sharing an edge is that edge.
+func (p Polygon) IntersectAll(clipping Polygon) Collection {
+	result := Collection{Polygon: Polygon{}}
+	if len(p) == 0 || len(clipping) == 0 || !p.BoundingBox().Overlaps(clipping.BoundingBox()) {
+		return result
+	}
+
+	polygons := connector{operation: INTERSECTION}
+	lines := connector{operation: CLIPLINE}
+	covered := make(map[Point]bool) // points that are part of the polygons or lines
+	var vertices [2]map[Point]bool
+	vertices[0], vertices[1] = make(map[Point]bool), make(map[Point]bool)
+	var touching []Point
+
+	both := coverageSet{0, 1}
+	for _, e := range coverage([]Polygon{p, clipping}) {
+		// The polygons whose boundary contains e are those covering
+		// only one of its sides.
+		for id := 0; id < 2; id++ {
+			if e.below.contains(id) == e.above.contains(id) {
+				continue
+			}
+			for _, pt := range []Point{e.segment.start, e.segment.end} {
+				if !vertices[id][pt] {
+					vertices[id][pt] = true
+					if vertices[1-id][pt] {
+						touching = append(touching, pt)
+					}
+				}
+			}
+		}
+		switch {
+		case e.below.equals(both) || e.above.equals(both):
+			polygons.add(e.segment)
+		case len(e.below) == 1 && len(e.above) == 1:
+			// Each polygon covers one side of e only.
+			lines.add(e.segment)
+		default:
+			continue
+		}
+		covered[e.segment.start], covered[e.segment.end] = true, true
+	}
+
+	result.Polygon = polygons.toPolygon()
+	for _, ch := range lines.openPolys {
+		result.Lines = append(result.Lines, Contour(ch.points))
+	}
+	for _, ch := range lines.closedPolys {
+		result.Lines = append(result.Lines, append(Contour(ch.points), ch.points[0]))
+	}
+	for _, pt := range touching {
+		if !covered[pt] {
+			result.Points = append(result.Points, pt)
+		}
+	}
+	return result
+}
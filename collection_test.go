@@ -0,0 +1,45 @@
+package polyclip
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIntersectAll(t *testing.T) {
+	square := func(x, y, size float64) Polygon {
+		return Polygon{{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}}
+	}
+	cases := []struct {
+		name        string
+		a, b        Polygon
+		area        float64
+		linesLength float64
+		points      []Point
+	}{
+		{"disjoint", square(0, 0, 1), square(5, 5, 1), 0, 0, nil},
+		{"overlapping", square(0, 0, 2), square(1, 1, 2), 1, 0, nil},
+		{"shared edge", square(0, 0, 1), square(1, 0, 1), 0, 1, nil},
+		{"partly shared edge", square(0, 0, 2), square(2, 1, 2), 0, 1, nil},
+		{"shared corner", square(0, 0, 1), square(1, 1, 1), 0, 0, []Point{{1, 1}}},
+		{"tip on edge", square(0, 0, 2), Polygon{{{1, 2}, {2, 3}, {0, 3}}}, 0, 0, []Point{{1, 2}}},
+		{"overlap and shared edge", square(0, 0, 2), Polygon{{{1, 1}, {3, 1}, {3, 3}, {2, 3}, {2, 2}, {1, 2}}}, 1, 0, nil},
+		{"overlap and separate shared edge",
+			Polygon{{{0, 0}, {3, 0}, {3, 1}, {1, 1}, {1, 2}, {0, 2}}},
+			Polygon{{{0.5, 0.5}, {2, 0.5}, {2, 1}, {1, 1}, {1, 2}, {2, 2}, {2, 3}, {0.5, 3}}},
+			1.25, 0, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := c.a.IntersectAll(c.b)
+			verify(t, area(r.Polygon) == c.area, "expected area %g, got %g (%v)", c.area, area(r.Polygon), r.Polygon)
+			var length float64
+			for _, l := range r.Lines {
+				for i := 0; i+1 < len(l); i++ {
+					length += Point{l[i+1].X - l[i].X, l[i+1].Y - l[i].Y}.Length()
+				}
+			}
+			verify(t, length == c.linesLength, "expected lines of length %g, got %v", c.linesLength, r.Lines)
+			verify(t, reflect.DeepEqual(r.Points, c.points), "expected points %v, got %v", c.points, r.Points)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package polyclip
+
+import "math"
+
+// conditioner maps points p to (p - offset) * scale, and back. The offset is
+// only non-zero along axes where subtracting it from any coordinate within the
+// operands is exact, and scale is a power of two, so mapping an input vertex
+// and back yields the vertex itself.
+type conditioner struct {
+	offset Point
+	scale  float64
+}
+
+// tinyExtent is the extent below which operands are scaled whatever the mode.
+const tinyExtent = 0x1p-30
+
+// newConditioner returns the conditioner for operands within bb, and false if
+// they need not be moved.
+func newConditioner(mode Conditioning, bb Rectangle) (conditioner, bool) {
+	if mode == CONDITION_NONE {
+		return conditioner{}, false
+	}
+	t := conditioner{Point{centre(bb.Min.X, bb.Max.X), centre(bb.Min.Y, bb.Max.Y)}, 1}
+	extent := math.Max(
+		math.Max(math.Abs(bb.Min.X-t.offset.X), math.Abs(bb.Max.X-t.offset.X)),
+		math.Max(math.Abs(bb.Min.Y-t.offset.Y), math.Abs(bb.Max.Y-t.offset.Y)))
+	// Operands smaller than tinyExtent are scaled in either mode, as the absolute
+	// tolerances of the sweep would take most of their vertices as equal.
+	// Keep away from overflow and subnormal numbers, where scaling is not exact.
+	if _, exp := math.Frexp(extent); (mode == CONDITION_SCALE || extent < tinyExtent) &&
+		extent > 0 && exp > -900 && exp < 900 {
+		t.scale = math.Ldexp(1, -exp)
+	}
+	return t, t.offset != Point{} || t.scale != 1
+}
+
+// centre returns a value close to the middle of lo and hi if the interval
+// between them lies far from the origin relative to its width, such that
+// subtracting it from any value in the interval is exact, and 0 otherwise.
+func centre(lo, hi float64) float64 {
+	// By Sterbenz' lemma, x - c is exact for c/2 <= x <= 2c, which holds for
+	// all x and c in [lo, hi] if hi <= 2lo, and likewise for negative values.
+	if !(lo > 0 && hi <= 2*lo) && !(hi < 0 && lo >= 2*hi) {
+		return 0
+	}
+	return math.Max(lo, math.Min(hi, lo+(hi-lo)/2))
+}
+
+// apply returns a conditioned copy of p.
+func (t conditioner) apply(p Polygon) Polygon {
+	r := make(Polygon, len(p))
+	for i, c := range p {
+		r[i] = make(Contour, len(c))
+		for j, pt := range c {
+			r[i][j] = Point{(pt.X - t.offset.X) * t.scale, (pt.Y - t.offset.Y) * t.scale}
+		}
+	}
+	return r
+}
+
+// revert maps the points of p, a result computed from conditioned operands, back in place.
+func (t conditioner) revert(p Polygon) {
+	for _, c := range p {
+		for j, pt := range c {
+			c[j] = t.revertPoint(pt)
+		}
+	}
+}
+
+// revertPoint maps a conditioned point back.
+func (t conditioner) revertPoint(p Point) Point {
+	return Point{p.X/t.scale + t.offset.X, p.Y/t.scale + t.offset.Y}
+}
+
+// condition moves polys, the operands of a sweep, as selected by
+// c.conditioning, and returns the moved copies, or polys itself if they need
+// not be moved. Results of the sweep are then mapped back by revert.
+func (c *clipper) condition(polys ...Polygon) []Polygon {
+	var bb Rectangle
+	empty := true
+	for _, p := range polys {
+		if p.NumVertices() == 0 {
+			continue
+		}
+		if empty {
+			bb, empty = p.BoundingBox(), false
+		} else {
+			bb = bb.union(p.BoundingBox())
+		}
+	}
+	cond, ok := newConditioner(c.conditioning, bb)
+	if empty || !ok {
+		return polys
+	}
+	c.cond = &cond
+	moved := make([]Polygon, len(polys))
+	for i, p := range polys {
+		moved[i] = cond.apply(p)
+	}
+	return moved
+}
+
+// revert maps results computed from operands moved by condition back in
+// place, along with the diagnostics.
+func (c *clipper) revert(results ...Polygon) {
+	if c.cond == nil {
+		return
+	}
+	for _, p := range results {
+		c.cond.revert(p)
+	}
+	if c.diagnostics != nil {
+		c.diagnostics.transform(c.cond.revertPoint)
+	}
+}
+
+// revertPoint maps a point computed from operands moved by condition back.
+func (c *clipper) revertPoint(p Point) Point {
+	if c.cond == nil {
+		return p
+	}
+	return c.cond.revertPoint(p)
+}
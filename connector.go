@@ -23,21 +23,98 @@
 
 package polyclip
 
+import "math"
+
 // Holds intermediate results (pointChains) of the clipping operation and forms them into
 // the final polygon.
 type connector struct {
 	openPolys   []chain
 	closedPolys []chain
 	operation   Op
+
+	// If not nil, records the origin of every added segment, keyed by its
+	// left and right points.
+	origins map[segment][]EdgeOrigin
+
+	// If positive, endpoints within epsilon of an endpoint added before are
+	// replaced by it, so that points computed twice with slightly different
+	// results still link up. The points seen so far are kept in grid, hashed
+	// by cells of size epsilon.
+	epsilon float64
+	grid    map[gridCell][]Point
+
+	// If positive, toPolygon joins and closes chains left open whose ends lie
+	// within repairGap of each other, rather than dropping them.
+	repairGap float64
+
+	stats  *Stats // if not nil, counts the chain operations
+	tracer Tracer // if not nil, passed the chains closed
+}
+
+// gridCell identifies a cell of the connector's spatial hash.
+type gridCell struct {
+	x, y int64
+}
+
+// addEdge adds the segment associated with e, recording its origin if needed.
+func (c *connector) addEdge(e *endpoint) {
+	l, r := e.leftRight()
+	l, r = c.canonical(l), c.canonical(r)
+	if c.origins != nil {
+		key := segment{l, r}
+		if pointLess(r, l) {
+			key = segment{r, l}
+		}
+		c.origins[key] = append(c.origins[key], EdgeOrigin{Source: Source(e.polygonType), Contour: e.contour, Edge: e.edge})
+	}
+	c.add(e.segment())
+}
+
+// canonical returns the point added before within epsilon of p, or else p
+// itself, which is then remembered.
+func (c *connector) canonical(p Point) Point {
+	if c.epsilon <= 0 {
+		return p
+	}
+	if c.grid == nil {
+		c.grid = make(map[gridCell][]Point)
+	}
+	cell := c.cell(p)
+	for x := cell.x - 1; x <= cell.x+1; x++ {
+		for y := cell.y - 1; y <= cell.y+1; y++ {
+			for _, q := range c.grid[gridCell{x, y}] {
+				if distance(p, q) <= c.epsilon {
+					return q
+				}
+			}
+		}
+	}
+	c.grid[cell] = append(c.grid[cell], p)
+	return p
+}
+
+// cell returns the cell of the spatial hash containing p. Cells far out are
+// merged to avoid overflowing, which only makes lookups there slower.
+func (c *connector) cell(p Point) gridCell {
+	const limit = 1 << 62
+	index := func(v float64) int64 {
+		return int64(math.Max(-limit, math.Min(limit, math.Floor(v/c.epsilon))))
+	}
+	return gridCell{index(p.X), index(p.Y)}
 }
 
 func (c *connector) add(s segment) {
+	s.start, s.end = c.canonical(s.start), c.canonical(s.end)
+	if c.epsilon > 0 && s.start.Equals(s.end) {
+		return // collapsed into a point
+	}
 	// j iterates through the openPolygon chains.
 	for j := range c.openPolys {
 		chain := &c.openPolys[j]
 		if !chain.linkSegment(s) {
 			continue
 		}
+		c.stats.count(statSegmentLinked)
 
 		if chain.closed {
 			if len(chain.points) == 2 {
@@ -48,6 +125,7 @@ func (c *connector) add(s segment) {
 				return
 			}
 			// move the chain from openPolys to closedPolys
+			c.closed(chain)
 			c.closedPolys = append(c.closedPolys, c.openPolys[j])
 			c.openPolys = append(c.openPolys[:j], c.openPolys[j+1:]...)
 			return
@@ -60,6 +138,7 @@ func (c *connector) add(s segment) {
 			// We won't be able to connect this to any of the chains preceding this one
 			// because we know that linkSegment failed on those.
 			if chain.linkChain(&c.openPolys[i]) {
+				c.stats.count(statChainJoined)
 				// delete
 				c.openPolys = append(c.openPolys[:i], c.openPolys[i+1:]...)
 				return
@@ -69,6 +148,7 @@ func (c *connector) add(s segment) {
 	}
 
 	// The segment cannot be connected with any open polygon
+	c.stats.count(statChainStarted)
 	c.openPolys = append(c.openPolys, *newChain(s))
 }
 
@@ -83,6 +163,7 @@ func (c *connector) toPolygon() Polygon {
 			poly.Add(con)
 		}
 	} else {
+		c.repair()
 		for _, chain := range c.closedPolys {
 			con := Contour{}
 			for _, p := range chain.points {
@@ -93,3 +174,101 @@ func (c *connector) toPolygon() Polygon {
 	}
 	return poly
 }
+
+// closed records that ch was closed.
+func (c *connector) closed(ch *chain) {
+	c.stats.count(statChainClosed)
+	if c.tracer != nil {
+		c.tracer.Trace(TraceEvent{Kind: TRACE_CLOSED, Points: append([]Point(nil), ch.points...)})
+	}
+}
+
+// repair closes the open chains whose ends lie within repairGap of each other,
+// first joining chains whose ends lie within it, nearest ends first. Chains
+// that cannot be closed are left open, and so dropped from polygon results.
+func (c *connector) repair() {
+	if c.repairGap <= 0 {
+		return
+	}
+	var open []chain
+	for len(c.openPolys) > 0 {
+		ch := c.openPolys[0]
+		c.openPolys = c.openPolys[1:]
+		for {
+			front, back := ch.points[0], ch.points[len(ch.points)-1]
+			best := math.Inf(1)
+			if d := distance(front, back); d <= c.repairGap {
+				best = d // closing ch beats joining farther ends
+			}
+			join, atBack, toFront := -1, false, false
+			for i, other := range c.openPolys {
+				otherFront, otherBack := other.points[0], other.points[len(other.points)-1]
+				for _, e := range [4]struct {
+					d               float64
+					atBack, toFront bool
+				}{
+					{distance(back, otherFront), true, true},
+					{distance(back, otherBack), true, false},
+					{distance(front, otherFront), false, true},
+					{distance(front, otherBack), false, false},
+				} {
+					if e.d <= c.repairGap && e.d < best {
+						best, join, atBack, toFront = e.d, i, e.atBack, e.toFront
+					}
+				}
+			}
+			if join < 0 {
+				break
+			}
+			// The joined ends are merged into the end of ch.
+			other := c.openPolys[join].points
+			c.openPolys = append(c.openPolys[:join], c.openPolys[join+1:]...)
+			c.stats.count(statChainJoined)
+			if atBack != toFront {
+				other = reversed(other)
+			}
+			if atBack {
+				ch.points = append(ch.points, other[1:]...)
+			} else {
+				ch.points = append(other[:len(other)-1:len(other)-1], ch.points...)
+			}
+		}
+		if n := len(ch.points); n > 3 && distance(ch.points[0], ch.points[n-1]) <= c.repairGap {
+			ch.points = ch.points[:n-1]
+			ch.closed = true
+			c.closed(&ch)
+			c.closedPolys = append(c.closedPolys, ch)
+		} else {
+			open = append(open, ch)
+		}
+	}
+	c.openPolys = open
+}
+
+// provenance looks up the recorded origin of every edge of poly, which must
+// have been produced by toPolygon. Edges without a recorded origin get
+// Contour and Edge set to -1.
+func (c *connector) provenance(poly Polygon) Provenance {
+	prov := make(Provenance, len(poly))
+	for i, con := range poly {
+		n := len(con)
+		if c.operation == CLIPLINE {
+			n-- // line strings are not closed
+		}
+		prov[i] = make([]EdgeOrigin, 0, n)
+		for j := 0; j < n; j++ {
+			key := con.segment(j)
+			if pointLess(key.end, key.start) {
+				key.start, key.end = key.end, key.start
+			}
+			origins := c.origins[key]
+			if len(origins) == 0 {
+				prov[i] = append(prov[i], EdgeOrigin{Contour: -1, Edge: -1})
+				continue
+			}
+			prov[i] = append(prov[i], origins[0])
+			c.origins[key] = origins[1:]
+		}
+	}
+	return prov
+}
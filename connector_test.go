@@ -20,6 +20,7 @@
 package polyclip
 
 import (
+	"math"
 	. "testing"
 )
 
@@ -59,3 +60,86 @@ func TestConnectorAddClosing1(t *T) {
 	}
 
 }
+
+func TestConnectorEpsilon(t *T) {
+	// The corner at (1, 1) is computed twice, differing in the last bit.
+	off := Point{math.Nextafter(1, 2), 1}
+	square := []segment{
+		{Point{0, 0}, Point{1, 0}},
+		{Point{1, 0}, Point{1, 1}},
+		{off, Point{0, 1}},
+		{Point{0, 1}, Point{0, 0}},
+	}
+	for _, x := range []struct {
+		epsilon  float64
+		contours int
+	}{{0, 0}, {1e-9, 1}} {
+		c := connector{operation: UNION, epsilon: x.epsilon}
+		for _, s := range square {
+			c.add(s)
+		}
+		poly := c.toPolygon()
+		verify(t, len(poly) == x.contours, "epsilon %v: expected %d contours, got %v", x.epsilon, x.contours, poly)
+		if len(poly) == 1 {
+			verify(t, len(poly[0]) == 4, "epsilon %v: expected 4 vertices, got %v", x.epsilon, poly)
+		}
+	}
+}
+
+func TestConnectorEpsilonAcrossCells(t *T) {
+	// Points on either side of a cell border, and at a corner of four cells.
+	c := connector{operation: UNION, epsilon: 0.5}
+	for _, x := range []struct{ p, want Point }{
+		{Point{0.99, 0.99}, Point{0.99, 0.99}},
+		{Point{1.01, 1.01}, Point{0.99, 0.99}},
+		{Point{1.01, 0.6}, Point{0.99, 0.99}},
+		{Point{1.2, 0.2}, Point{1.2, 0.2}},
+		{Point{-0.99, -0.99}, Point{-0.99, -0.99}},
+		{Point{-1.01, -0.99}, Point{-0.99, -0.99}},
+	} {
+		got := c.canonical(x.p)
+		verify(t, got == x.want, "%v: expected %v, got %v", x.p, x.want, got)
+	}
+}
+
+func TestConnectorRepair(t *T) {
+	gap := 1e-12
+	cases := []struct {
+		open     [][]Point
+		contours int
+	}{
+		{ // a single chain, almost closed
+			[][]Point{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, gap}}},
+			1,
+		},
+		{ // two halves, one of them reversed, meeting almost
+			[][]Point{{{0, 0}, {1, 0}, {1, 1}}, {{0, gap}, {0, 1}, {1, 1 + gap}}},
+			1,
+		},
+		{ // too far apart
+			[][]Point{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0.1}}},
+			0,
+		},
+		{ // too short to make a polygon
+			[][]Point{{{0, 0}, {1, 0}, {gap, 0}}},
+			0,
+		},
+	}
+	for i, x := range cases {
+		c := connopen(x.open...)
+		c.operation = UNION
+		c.repairGap = 1e-9
+		poly := c.toPolygon()
+		verify(t, len(poly) == x.contours, "Case %d: expected %d contours, got %v", i, x.contours, poly)
+		if len(poly) == 1 {
+			verify(t, len(poly[0]) == 4 && circa(area(poly), 1), "Case %d: expected the unit square, got %v", i, poly)
+		}
+
+		// Without a repair gap, as without WithLinkTolerance, open chains
+		// are dropped.
+		c = connopen(x.open...)
+		c.operation = UNION
+		poly = c.toPolygon()
+		verify(t, len(poly) == 0, "Case %d: expected no contours without repair, got %v", i, poly)
+	}
+}
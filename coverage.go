@@ -0,0 +1,216 @@
+package polyclip
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Face is a part of the plane covered by the same set of input polygons.
+type Face struct {
+	IDs     []int   // Indices of the covering polygons, in increasing order
+	Polygon Polygon // May consist of several disjoint contours
+	Area    float64
+}
+
+// OverlayN overlays any number of polygons in a single sweep, and returns the
+// faces of the resulting subdivision of the plane, labelled with the indices
+// of the polygons covering them. All parts of the plane covered by the same
+// set of polygons make up one Face; the uncovered part is not returned.
+// Faces are sorted by their IDs.
+// The polygons should not be self-intersecting; see Simplify.
+func OverlayN(polys []Polygon) []Face {
+	connectors := make(map[string]*connector)
+	faces := make(map[string]*Face)
+	face := func(ids coverageSet) (*connector, *Face) {
+		key := ids.key()
+		if f, ok := faces[key]; ok {
+			return connectors[key], f
+		}
+		conn := &connector{operation: UNION}
+		f := &Face{IDs: append([]int{}, ids...)}
+		connectors[key], faces[key] = conn, f
+		return conn, f
+	}
+
+	for _, e := range coverage(polys) {
+		if len(e.below) > 0 {
+			conn, f := face(e.below)
+			conn.add(e.segment)
+			f.Area += e.trapezoid() // e bounds the face from above
+		}
+		if len(e.above) > 0 {
+			conn, f := face(e.above)
+			conn.add(e.segment)
+			f.Area -= e.trapezoid() // e bounds the face from below
+		}
+	}
+
+	result := make([]Face, 0, len(faces))
+	for key, f := range faces {
+		f.Polygon = connectors[key].toPolygon()
+		result = append(result, *f)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].IDs, result[j].IDs
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return result
+}
+
+// CoverageCount returns the part of the plane where the number of polygons
+// covering it satisfies predicate. For example, the part covered by at least
+// two of polys is
+//
+//	CoverageCount(polys, func(n int) bool { return n >= 2 })
+//
+// and the part covered by exactly one of them is
+//
+//	CoverageCount(polys, func(n int) bool { return n == 1 })
+//
+// Since the result must be bounded, predicate(0) must be false.
+// All polygons are processed in a single sweep, tracking the covering
+// polygons on either side of every edge.
+func CoverageCount(polys []Polygon, predicate func(count int) bool) Polygon {
+	conn := connector{operation: UNION}
+	for _, e := range coverage(polys) {
+		if predicate(len(e.below)) != predicate(len(e.above)) {
+			conn.add(e.segment)
+		}
+	}
+	return conn.toPolygon()
+}
+
+// coverageSet holds the indices of the polygons covering a part of the plane,
+// in increasing order.
+type coverageSet []int
+
+// toggle returns a copy of s with id added, or removed if it was already present.
+func (s coverageSet) toggle(id int) coverageSet {
+	i := sort.SearchInts(s, id)
+	r := make(coverageSet, 0, len(s)+1)
+	r = append(r, s[:i]...)
+	if i < len(s) && s[i] == id {
+		return append(r, s[i+1:]...)
+	}
+	r = append(r, id)
+	return append(r, s[i:]...)
+}
+
+func (s coverageSet) contains(id int) bool {
+	i := sort.SearchInts(s, id)
+	return i < len(s) && s[i] == id
+}
+
+func (s coverageSet) equals(o coverageSet) bool {
+	if len(s) != len(o) {
+		return false
+	}
+	for i := range s {
+		if s[i] != o[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s coverageSet) key() string {
+	return fmt.Sprint([]int(s))
+}
+
+// coveredEdge is a part of the boundary of one or more input polygons,
+// together with the sets of polygons covering the plane on either side.
+// For vertical edges, "below" is the right-hand side.
+type coveredEdge struct {
+	segment      segment // from left to right
+	below, above coverageSet
+}
+
+// trapezoid returns the signed area between the edge and the x axis.
+func (e coveredEdge) trapezoid() float64 {
+	l, r := e.segment.start, e.segment.end
+	return (r.X - l.X) * (l.Y + r.Y) / 2
+}
+
+// coverage divides the boundaries of polys at all their intersections, and
+// determines which polygons cover each side of the resulting edges. Edges
+// that do not separate differently covered parts of the plane are dropped.
+func coverage(polys []Polygon) []coveredEdge {
+	c := new(clipper)
+	polys = c.condition(polys...)
+	for i, p := range polys {
+		for j, cont := range p {
+			for k := range cont {
+				// The polygon type doubles as the index of the input polygon.
+				addProcessedSegment(&c.eventQueue, cont.segment(k), polygonType(i), j, k)
+			}
+		}
+	}
+
+	// Crossing the boundary of a polygon toggles whether it covers the plane,
+	// so the coverage right above a segment is that below it, toggled by
+	// the segment's own polygon.
+	below := make(map[*endpoint]coverageSet)
+	prevOf := make(map[*endpoint]*endpoint)
+	original := func(e *endpoint) segment { return polys[e.polygonType][e.contour].segment(e.edge) }
+	pieces := c.subdivide(c.splitAtIntersections(original), func(e, prev *endpoint) {
+		prevOf[e] = prev
+		if prev == nil {
+			below[e] = nil
+			return
+		}
+		below[e] = below[prev].toggle(int(prev.polygonType))
+	})
+
+	// Overlapping edges of different polygons have been divided into identical
+	// pieces, stacked on top of each other in the sweep line. Each such stack
+	// makes up a single edge, between the parts below its bottom piece and
+	// above its top piece.
+	stacks := make(map[segment][]*endpoint)
+	var order []segment
+	for _, r := range pieces {
+		s := segment{r.other.p, r.p}
+		if _, ok := stacks[s]; !ok {
+			order = append(order, s)
+		}
+		stacks[s] = append(stacks[s], r.other)
+	}
+
+	var edges []coveredEdge
+	for _, s := range order {
+		stack := stacks[s]
+		bottom := stack[0]
+		for _, e := range stack {
+			if !inStack(prevOf[e], stack) {
+				bottom = e
+				break
+			}
+		}
+		above := below[bottom]
+		for _, e := range stack {
+			above = above.toggle(int(e.polygonType))
+		}
+		if above.equals(below[bottom]) {
+			continue
+		}
+		s = segment{c.revertPoint(s.start), c.revertPoint(s.end)}
+		if s.start.Equals(s.end) {
+			continue // collapsed when moved back
+		}
+		edges = append(edges, coveredEdge{segment: s, below: below[bottom], above: above})
+	}
+	return edges
+}
+
+func inStack(e *endpoint, stack []*endpoint) bool {
+	for _, s := range stack {
+		if s == e {
+			return true
+		}
+	}
+	return false
+}
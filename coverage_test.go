@@ -0,0 +1,96 @@
+package polyclip
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestOverlayN(t *testing.T) {
+	square := func(x, y, size float64) Polygon {
+		return Polygon{{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}}
+	}
+	cases := []struct {
+		name  string
+		polys []Polygon
+		want  map[string]float64 // area by IDs
+	}{
+		{
+			name:  "two overlapping",
+			polys: []Polygon{square(0, 0, 2), square(1, 1, 2)},
+			want:  map[string]float64{"[0]": 3, "[1]": 3, "[0 1]": 1},
+		},
+		{
+			name:  "three overlapping",
+			polys: []Polygon{square(0, 0, 2), square(1, 0, 2), square(0.5, 1, 2)},
+			want: map[string]float64{
+				"[0]": 1.5, "[1]": 1.5, "[2]": 2,
+				"[0 1]": 1, "[0 2]": 0.5, "[1 2]": 0.5, "[0 1 2]": 1,
+			},
+		},
+		{
+			name:  "shared edges",
+			polys: []Polygon{square(0, 0, 1), square(1, 0, 1), square(0, 0, 2)},
+			want:  map[string]float64{"[0 2]": 1, "[1 2]": 1, "[2]": 2},
+		},
+		{
+			name:  "identical",
+			polys: []Polygon{square(0, 0, 1), square(0, 0, 1)},
+			want:  map[string]float64{"[0 1]": 1},
+		},
+		{
+			name:  "hole",
+			polys: []Polygon{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}, {{1, 1}, {3, 1}, {3, 3}, {1, 3}}}, square(2, 2, 4)},
+			want:  map[string]float64{"[0]": 9, "[1]": 13, "[0 1]": 3},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			faces := OverlayN(c.polys)
+			got := make(map[string]float64)
+			for _, f := range faces {
+				key := coverageSet(f.IDs).key()
+				got[key] = f.Area
+				if a := area(f.Polygon); math.Abs(a-f.Area) > 1e-9 {
+					t.Errorf("%s: area of geometry %v is %g, but Area is %g", key, f.Polygon, a, f.Area)
+				}
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("expected %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestCoverageCount(t *testing.T) {
+	polys := []Polygon{
+		{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}},
+		{{{1, 0}, {3, 0}, {3, 2}, {1, 2}}},
+		{{{0.5, 1}, {2.5, 1}, {2.5, 3}, {0.5, 3}}},
+	}
+	cases := []struct {
+		name      string
+		predicate func(int) bool
+		area      float64
+	}{
+		{"at least 1", func(n int) bool { return n >= 1 }, 8},
+		{"at least 2", func(n int) bool { return n >= 2 }, 3},
+		{"exactly 1", func(n int) bool { return n == 1 }, 5},
+		{"exactly 2", func(n int) bool { return n == 2 }, 2},
+		{"all 3", func(n int) bool { return n == 3 }, 1},
+		{"more than 3", func(n int) bool { return n > 3 }, 0},
+	}
+	for _, c := range cases {
+		result := CoverageCount(polys, c.predicate)
+		if a := area(result); math.Abs(a-c.area) > 1e-9 {
+			t.Errorf("%s: expected area %g, got %g (%v)", c.name, c.area, a, result)
+		}
+	}
+}
+
+func TestCoverageSetToggle(t *testing.T) {
+	s := coverageSet(nil).toggle(3).toggle(1).toggle(2)
+	verify(t, reflect.DeepEqual(s, coverageSet{1, 2, 3}), "expected [1 2 3], got %v", s)
+	s = s.toggle(2)
+	verify(t, reflect.DeepEqual(s, coverageSet{1, 3}), "expected [1 3], got %v", s)
+}
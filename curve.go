@@ -0,0 +1,200 @@
+package polyclip
+
+import "math"
+
+// maxCurveSegments bounds the number of segments a single curve is flattened
+// into, however small the tolerance.
+const maxCurveSegments = 1 << 16
+
+// Arc returns points along the circular arc around center from angle start to
+// angle end (in radians, counter-clockwise if end > start), including both ends.
+// Consecutive points are close enough for the chords between them to deviate
+// from the arc by at most tolerance.
+func Arc(center Point, radius, start, end, tolerance float64) Contour {
+	return ellipseArc(center, radius, radius, 0, start, end, tolerance, true)
+}
+
+// Circle returns a contour approximating the circle around center, with the
+// edges deviating from the circle by at most tolerance.
+func Circle(center Point, radius, tolerance float64) Contour {
+	return Ellipse(center, radius, radius, 0, tolerance)
+}
+
+// Ellipse returns a contour approximating the ellipse around center with radii
+// rx and ry, rotated counter-clockwise by rotation radians, with the edges
+// deviating from the ellipse by at most tolerance.
+func Ellipse(center Point, rx, ry, rotation, tolerance float64) Contour {
+	return ellipseArc(center, rx, ry, rotation, 0, 2*math.Pi, tolerance, false)
+}
+
+// ellipseArc returns points along an elliptical arc between the parametric
+// angles start and end. The end point is left out if withEnd is false, as for
+// a full ellipse, where it equals the start point.
+func ellipseArc(center Point, rx, ry, rotation, start, end, tolerance float64, withEnd bool) Contour {
+	// The chord of an arc of angle θ on a circle of radius r deviates from it by
+	// r(1 - cos(θ/2)); using the larger radius keeps the ellipse within tolerance.
+	r := math.Max(math.Abs(rx), math.Abs(ry))
+	n := maxCurveSegments
+	if r <= tolerance {
+		n = 1
+	} else if step := 2 * math.Acos(1-tolerance/r); step > 0 {
+		n = int(math.Min(math.Ceil(math.Abs(end-start)/step), maxCurveSegments))
+	}
+	if !withEnd && n < 3 {
+		n = 3 // keep closed shapes from collapsing into a line
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	sinRot, cosRot := math.Sincos(rotation)
+	points := n
+	if withEnd {
+		points++
+	}
+	c := make(Contour, points)
+	for i := range c {
+		angle := start + (end-start)*float64(i)/float64(n)
+		if i == n {
+			angle = end
+		}
+		sin, cos := math.Sincos(angle)
+		x, y := rx*cos, ry*sin
+		c[i] = Point{center.X + x*cosRot - y*sinRot, center.Y + x*sinRot + y*cosRot}
+	}
+	return c
+}
+
+// QuadraticBezier returns points along the quadratic Bézier curve from p0 to p2
+// with control point p1, including both ends, such that the chords between them
+// deviate from the curve by at most tolerance.
+func QuadraticBezier(p0, p1, p2 Point, tolerance float64) Contour {
+	// Elevate to a cubic with the same shape.
+	c1 := Point{p0.X + 2*(p1.X-p0.X)/3, p0.Y + 2*(p1.Y-p0.Y)/3}
+	c2 := Point{p2.X + 2*(p1.X-p2.X)/3, p2.Y + 2*(p1.Y-p2.Y)/3}
+	return CubicBezier(p0, c1, c2, p2, tolerance)
+}
+
+// CubicBezier returns points along the cubic Bézier curve from p0 to p3 with
+// control points p1 and p2, including both ends, such that the chords between
+// them deviate from the curve by at most tolerance.
+func CubicBezier(p0, p1, p2, p3 Point, tolerance float64) Contour {
+	c := Contour{p0}
+	flattenCubic(&c, p0, p1, p2, p3, tolerance, 0)
+	return c
+}
+
+// flattenCubic appends points along the curve after p0 to c, halving the curve
+// until it is flat enough: the curve lies within the convex hull of its control
+// points, so it deviates from the chord by at most the distance of the control
+// points from it.
+func flattenCubic(c *Contour, p0, p1, p2, p3 Point, tolerance float64, depth int) {
+	chord := segment{p0, p3}
+	flat := distance(p1, chord.closestPoint(p1)) <= tolerance && distance(p2, chord.closestPoint(p2)) <= tolerance
+	if flat || depth >= 16 {
+		c.Add(p3)
+		return
+	}
+	mid := func(a, b Point) Point { return Point{(a.X + b.X) / 2, (a.Y + b.Y) / 2} }
+	// de Casteljau's subdivision at t = 1/2.
+	p01, p12, p23 := mid(p0, p1), mid(p1, p2), mid(p2, p3)
+	p012, p123 := mid(p01, p12), mid(p12, p23)
+	m := mid(p012, p123)
+	flattenCubic(c, p0, p01, p012, m, tolerance, depth+1)
+	flattenCubic(c, m, p123, p23, p3, tolerance, depth+1)
+}
+
+// PathBuilder builds a polygon from a path of lines and curves, as found in SVG
+// or CAD drawings, flattening the curves with the given tolerance:
+//
+//	b := NewPathBuilder(0.01)
+//	b.MoveTo(Point{0, 0})
+//	b.LineTo(Point{2, 0})
+//	b.ArcTo(Point{2, 1}, math.Pi)
+//	b.Close()
+//	p := b.Polygon()
+//
+// Each MoveTo starts a new contour. Since polygon contours are always closed,
+// Close is optional.
+type PathBuilder struct {
+	tolerance float64
+	poly      Polygon
+	current   Contour
+}
+
+// NewPathBuilder returns an empty PathBuilder flattening curves so that the
+// result deviates from them by at most tolerance.
+func NewPathBuilder(tolerance float64) *PathBuilder {
+	return &PathBuilder{tolerance: tolerance}
+}
+
+// MoveTo starts a new contour at p.
+func (b *PathBuilder) MoveTo(p Point) {
+	b.Close()
+	b.current = Contour{p}
+}
+
+// pen returns the current point, which is the origin if nothing was drawn yet.
+func (b *PathBuilder) pen() Point {
+	if len(b.current) == 0 {
+		return Point{}
+	}
+	return b.current[len(b.current)-1]
+}
+
+// add appends points to the current contour, skipping repeated points.
+func (b *PathBuilder) add(points ...Point) {
+	for _, p := range points {
+		if len(b.current) == 0 || !b.pen().Equals(p) {
+			b.current.Add(p)
+		}
+	}
+}
+
+// LineTo adds a straight line from the current point to p.
+func (b *PathBuilder) LineTo(p Point) {
+	b.add(b.pen(), p)
+}
+
+// ArcTo adds a circular arc from the current point around center, turning by
+// angle radians (counter-clockwise if positive).
+func (b *PathBuilder) ArcTo(center Point, angle float64) {
+	from := b.pen()
+	d := Point{from.X - center.X, from.Y - center.Y}
+	start := math.Atan2(d.Y, d.X)
+	arc := Arc(center, d.Length(), start, start+angle, b.tolerance)
+	arc[0] = from // exactly, despite rounding
+	b.add(arc...)
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to p, with control point c.
+func (b *PathBuilder) QuadTo(c, p Point) {
+	b.add(QuadraticBezier(b.pen(), c, p, b.tolerance)...)
+}
+
+// CurveTo adds a cubic Bézier curve from the current point to p, with control points c1 and c2.
+func (b *PathBuilder) CurveTo(c1, c2, p Point) {
+	b.add(CubicBezier(b.pen(), c1, c2, p, b.tolerance)...)
+}
+
+// Close closes the current contour, and adds it to the polygon unless it has
+// fewer than three points. Drawing after Close continues from the start of
+// the closed contour, in a new contour, as in SVG paths.
+func (b *PathBuilder) Close() {
+	c := b.current
+	if len(c) > 1 && c[0].Equals(c[len(c)-1]) {
+		c = c[:len(c)-1]
+	}
+	if len(c) >= 3 {
+		b.poly.Add(c)
+	}
+	if len(b.current) > 0 {
+		b.current = Contour{b.current[0]}
+	}
+}
+
+// Polygon closes the current contour and returns the polygon built so far.
+func (b *PathBuilder) Polygon() Polygon {
+	b.Close()
+	return b.poly.Clone()
+}
@@ -0,0 +1,93 @@
+package polyclip
+
+import (
+	"math"
+	"testing"
+)
+
+// maxDeviation returns the largest distance of the points of the curve f,
+// sampled at many parameter values, from the polyline c.
+func maxDeviation(c Contour, f func(t float64) Point) float64 {
+	max := 0.
+	for i := 0; i <= 1000; i++ {
+		p := f(float64(i) / 1000)
+		max = math.Max(max, PolylineDistance(c, Contour{p}))
+	}
+	return max
+}
+
+func TestArc(t *testing.T) {
+	center := Point{1, 2}
+	for _, tol := range []float64{0.1, 0.01, 0.001} {
+		c := Arc(center, 5, 0, math.Pi/2, tol)
+		verify(t, pointsCirca(c[0], Point{6, 2}) && pointsCirca(c[len(c)-1], Point{1, 7}), "expected arc from {6 2} to {1 7}, got %v", c)
+		d := maxDeviation(c, func(t float64) Point {
+			return Point{1 + 5*math.Cos(t*math.Pi/2), 2 + 5*math.Sin(t*math.Pi/2)}
+		})
+		verify(t, d <= tol*1.0001, "tolerance %g: deviation %g", tol, d)
+		verify(t, len(c) > 2, "expected several points, got %v", c)
+	}
+	cw := Arc(Point{}, 1, math.Pi, 0, 0.01)
+	verify(t, pointsCirca(cw[len(cw)/2], Point{0, 1}), "expected clockwise arc through {0 1}, got %v", cw)
+}
+
+func TestCircleAndEllipse(t *testing.T) {
+	c := Circle(Point{}, 10, 0.01)
+	verify(t, !c[0].Equals(c[len(c)-1]), "expected contour without repeated end point")
+	verify(t, math.Abs(area(Polygon{c})-100*math.Pi) < 100*math.Pi*0.002, "expected area close to 100π, got %g", area(Polygon{c}))
+
+	e := Ellipse(Point{1, 1}, 4, 2, math.Pi/2, 0.01)
+	bb := e.BoundingBox()
+	verify(t, math.Abs(bb.Max.Y-5) < 0.01 && math.Abs(bb.Min.Y+3) < 0.01, "expected rotated ellipse to span y from -3 to 5, got %v", bb)
+	verify(t, math.Abs(bb.Max.X-3) < 0.01 && math.Abs(bb.Min.X+1) < 0.01, "expected rotated ellipse to span x from -1 to 3, got %v", bb)
+	verify(t, len(Circle(Point{}, 1, 10)) == 3, "expected a triangle for a huge tolerance")
+}
+
+func TestBezier(t *testing.T) {
+	p0, p1, p2, p3 := Point{0, 0}, Point{1, 3}, Point{3, -3}, Point{4, 0}
+	cubic := func(t float64) Point {
+		u := 1 - t
+		a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		return Point{a*p0.X + b*p1.X + c*p2.X + d*p3.X, a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y}
+	}
+	quad := func(t float64) Point {
+		u := 1 - t
+		return Point{u*u*p0.X + 2*u*t*p1.X + t*t*p3.X, u*u*p0.Y + 2*u*t*p1.Y + t*t*p3.Y}
+	}
+	for _, tol := range []float64{0.1, 0.001} {
+		c := CubicBezier(p0, p1, p2, p3, tol)
+		verify(t, c[0].Equals(p0) && c[len(c)-1].Equals(p3), "expected cubic from %v to %v, got %v", p0, p3, c)
+		verify(t, maxDeviation(c, cubic) <= tol, "cubic, tolerance %g: deviation %g", tol, maxDeviation(c, cubic))
+		q := QuadraticBezier(p0, p1, p3, tol)
+		verify(t, q[0].Equals(p0) && q[len(q)-1].Equals(p3), "expected quadratic from %v to %v, got %v", p0, p3, q)
+		verify(t, maxDeviation(q, quad) <= tol, "quadratic, tolerance %g: deviation %g", tol, maxDeviation(q, quad))
+	}
+	verify(t, len(CubicBezier(p0, Point{1, 0}, Point{2, 0}, p3, 0.01)) == 2, "expected a straight curve to stay a single segment")
+}
+
+func TestPathBuilder(t *testing.T) {
+	// A rectangle with a semicircular end, and a square hole.
+	b := NewPathBuilder(0.001)
+	b.MoveTo(Point{0, 0})
+	b.LineTo(Point{2, 0})
+	b.ArcTo(Point{2, 1}, math.Pi)
+	b.LineTo(Point{0, 2})
+	b.Close()
+	b.MoveTo(Point{0.5, 0.5})
+	b.LineTo(Point{1, 0.5})
+	b.QuadTo(Point{1, 0.75}, Point{1, 1})
+	b.CurveTo(Point{0.75, 1}, Point{0.75, 1}, Point{0.5, 1})
+	p := b.Polygon()
+
+	verify(t, len(p) == 2, "expected 2 contours, got %d", len(p))
+	want := 4 + math.Pi/2 - 0.25
+	verify(t, math.Abs(area(p)-want) < 0.01, "expected area %g, got %g", want, area(p))
+	for _, c := range p {
+		verify(t, !c[0].Equals(c[len(c)-1]), "expected contour without repeated end point, got %v", c)
+		for i := range c {
+			s := c.segment(i)
+			verify(t, !s.start.Equals(s.end), "unexpected repeated point in %v", c)
+		}
+	}
+	verify(t, len(NewPathBuilder(0.1).Polygon()) == 0, "expected empty polygon from empty path")
+}
@@ -0,0 +1,72 @@
+package polyclip
+
+import "math"
+
+// ddouble is an unevaluated sum hi + lo of two float64 values with |lo| at most
+// half an ulp of hi, giving about twice the precision of a float64.
+// Only the operations needed by findIntersection are provided.
+type ddouble struct {
+	hi, lo float64
+}
+
+// twoSum returns a+b exactly, as a rounded sum and its error.
+func twoSum(a, b float64) ddouble {
+	s := a + b
+	bb := s - a
+	return ddouble{s, (a - (s - bb)) + (b - bb)}
+}
+
+// twoProd returns a*b exactly, using a fused multiply-add for the error.
+func twoProd(a, b float64) ddouble {
+	p := a * b
+	return ddouble{p, math.FMA(a, b, -p)}
+}
+
+// renormalize returns hi+lo with the error term again smaller than half an ulp.
+func renormalize(hi, lo float64) ddouble {
+	s := hi + lo
+	return ddouble{s, lo - (s - hi)}
+}
+
+// ddDiff returns a-b exactly.
+func ddDiff(a, b float64) ddouble {
+	return twoSum(a, -b)
+}
+
+func (a ddouble) add(b ddouble) ddouble {
+	s := twoSum(a.hi, b.hi)
+	return renormalize(s.hi, s.lo+a.lo+b.lo)
+}
+
+func (a ddouble) sub(b ddouble) ddouble {
+	return a.add(ddouble{-b.hi, -b.lo})
+}
+
+func (a ddouble) mul(b ddouble) ddouble {
+	p := twoProd(a.hi, b.hi)
+	return renormalize(p.hi, p.lo+a.hi*b.lo+a.lo*b.hi)
+}
+
+func (a ddouble) div(b ddouble) ddouble {
+	q1 := a.hi / b.hi
+	r := a.sub(b.mul(ddouble{q1, 0}))
+	q2 := r.hi / b.hi
+	return renormalize(q1, q2)
+}
+
+// ddOrientation returns the sign of the signed area of the triangle p0, p1,
+// p2, computed in double-double arithmetic: positive if they are ordered
+// counterclockwise, negative if clockwise, and zero if they are collinear or
+// too nearly so to tell.
+func ddOrientation(p0, p1, p2 Point) int {
+	ax, ay := ddDiff(p1.X, p0.X), ddDiff(p1.Y, p0.Y)
+	bx, by := ddDiff(p2.X, p0.X), ddDiff(p2.Y, p0.Y)
+	a := ax.mul(by).sub(ay.mul(bx))
+	switch {
+	case a.hi > 0 || a.hi == 0 && a.lo > 0:
+		return 1
+	case a.hi < 0 || a.hi == 0 && a.lo < 0:
+		return -1
+	}
+	return 0
+}
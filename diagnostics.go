@@ -0,0 +1,67 @@
+package polyclip
+
+// Diagnostics lists the places where a Boolean operation lost geometry to
+// numerical problems, so that suspicious results can be flagged. A result
+// computed without any is not guaranteed to be correct, but one computed with
+// some is likely to miss edges or area.
+type Diagnostics struct {
+	// DroppedIntersections lists intersections of two segments that were
+	// ignored, as the point computed for them lies on the wrong side of both.
+	DroppedIntersections []DroppedDivision
+
+	// DroppedDivisions lists divisions of a segment at an intersection point
+	// that were discarded, as one of the parts would have been reversed or of
+	// zero length.
+	DroppedDivisions []DroppedDivision
+
+	// UnclosedChains lists chains of result edges that could not be closed
+	// into contours, and are therefore missing from a polygon result.
+	UnclosedChains []Contour
+}
+
+// DroppedDivision describes an intersection at which segments were not divided.
+type DroppedDivision struct {
+	Point    Point        // the intersection point
+	Segments [][2]Point   // the segments, each by its left and right endpoint
+	Origins  []EdgeOrigin // the input edges the segments are parts of
+}
+
+// Empty returns whether no geometry was found to be lost.
+func (d *Diagnostics) Empty() bool {
+	return len(d.DroppedIntersections) == 0 && len(d.DroppedDivisions) == 0 && len(d.UnclosedChains) == 0
+}
+
+// WithDiagnostics makes Construct fill d with the places where geometry was lost.
+func WithDiagnostics(d *Diagnostics) Option {
+	return func(c *clipper) {
+		c.diagnostics = d
+	}
+}
+
+// newDroppedDivision describes the division of the segments of es at p.
+func newDroppedDivision(p Point, es ...*endpoint) DroppedDivision {
+	d := DroppedDivision{Point: p}
+	for _, e := range es {
+		l, r := e.leftRight()
+		d.Segments = append(d.Segments, [2]Point{l, r})
+		d.Origins = append(d.Origins, EdgeOrigin{Source: Source(e.polygonType), Contour: e.contour, Edge: e.edge})
+	}
+	return d
+}
+
+// transform applies f to all points of d.
+func (d *Diagnostics) transform(f func(Point) Point) {
+	for _, list := range [][]DroppedDivision{d.DroppedIntersections, d.DroppedDivisions} {
+		for i := range list {
+			list[i].Point = f(list[i].Point)
+			for j, s := range list[i].Segments {
+				list[i].Segments[j] = [2]Point{f(s[0]), f(s[1])}
+			}
+		}
+	}
+	for _, c := range d.UnclosedChains {
+		for i := range c {
+			c[i] = f(c[i])
+		}
+	}
+}
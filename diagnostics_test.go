@@ -0,0 +1,58 @@
+package polyclip
+
+import "testing"
+
+func TestWithDiagnostics(t *testing.T) {
+	cases := []struct {
+		subject, clipping                  Polygon
+		intersections, divisions, unclosed int
+	}{
+		{ // clean
+			Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}},
+			Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}}},
+			0, 0, 0,
+		},
+		{ // a computed intersection point lies beyond the ends of both segments
+			Polygon{{{2.000000000000053, 1.837292162381256e-14}, {6.394604362599082e-15, 1.000000000000052}, {3.000000000000031, 3.0000000000000413}, {1.0000000000000326, 2.000000000000003}}},
+			Polygon{{{1.0000000000000817, 2.0000000000000533}, {1.0000000000000326, 2.000000000000027}, {3.000000000000035, 5.260892759439762e-14}}},
+			1, 0, 0,
+		},
+		{ // dividing a segment would reverse one of its parts
+			Polygon{{{3.0000000000000138, 2.0000000000000537}, {3.000000000000051, 6.530402051353607e-14}, {1.0000000000000655, 3.0000000000000635}, {2.000000000000003, 3.691117091643448e-14}}},
+			Polygon{{{3.476817085915696e-14, 2.0000000000000253}, {3.0000000000000555, 1.0000000000000506}, {1.000000000000033, 7.002878731458151e-14}}},
+			0, 1, 0,
+		},
+		{ // dropped divisions leave a chain unclosed
+			Polygon{{{2.0000000000000697, 1.0000000000000029}, {3.000000000000061, 1.000000000000008}, {1.000000000000006, 1.0000000000000302}, {1.0000000000000542, 2.000000000000028}}},
+			Polygon{{{1.000000000000053, 2.0000000000000284}, {3.000000000000036, 1.0000000000000298}, {1.0000000000000098, 7.429099894984302e-15}}},
+			0, 2, 1,
+		},
+	}
+	for i, x := range cases {
+		var d Diagnostics
+		x.subject.Construct(UNION, x.clipping, WithDiagnostics(&d))
+		verify(t, len(d.DroppedIntersections) == x.intersections, "Case %d: expected %d dropped intersections, got %+v", i, x.intersections, d.DroppedIntersections)
+		verify(t, len(d.DroppedDivisions) == x.divisions, "Case %d: expected %d dropped divisions, got %+v", i, x.divisions, d.DroppedDivisions)
+		verify(t, len(d.UnclosedChains) == x.unclosed, "Case %d: expected %d unclosed chains, got %v", i, x.unclosed, d.UnclosedChains)
+		verify(t, d.Empty() == (x.intersections+x.divisions+x.unclosed == 0), "Case %d: Empty returned %v for %+v", i, d.Empty(), d)
+
+		// The segments reported are parts of the input edges given as their origins.
+		for _, dd := range append(d.DroppedIntersections, d.DroppedDivisions...) {
+			verify(t, len(dd.Segments) == len(dd.Origins), "Case %d: %d segments, but %d origins", i, len(dd.Segments), len(dd.Origins))
+			for j, o := range dd.Origins {
+				in := []Polygon{x.subject, x.clipping}[o.Source][o.Contour].segment(o.Edge)
+				for _, p := range dd.Segments[j] {
+					verify(t, signedArea(in.start, in.end, p) == 0 || p.Equals(dd.Point),
+						"Case %d: %v is not on its origin %v", i, dd.Segments[j], in)
+				}
+			}
+		}
+	}
+}
+
+func TestWithDiagnosticsReset(t *testing.T) {
+	d := Diagnostics{UnclosedChains: []Contour{{{0, 0}, {1, 1}}}}
+	subject := Polygon{{{0, 0}, {1, 0}, {1, 1}}}
+	subject.Construct(UNION, Polygon{{{5, 5}, {6, 5}, {6, 6}}}, WithDiagnostics(&d))
+	verify(t, d.Empty(), "expected diagnostics to be cleared, got %+v", d)
+}
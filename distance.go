@@ -0,0 +1,476 @@
+package polyclip
+
+import (
+	"math"
+	"sort"
+)
+
+// Distance returns the minimum distance between the polygons p and q,
+// which is 0 if they intersect or one lies within the other, and +Inf if
+// either is empty.
+func (p Polygon) Distance(q Polygon) float64 {
+	ps, qs := p.segments(), q.segments()
+	if len(ps) == 0 || len(qs) == 0 {
+		return math.Inf(1)
+	}
+	a, b := closestPoints(p, q, ps, qs)
+	return distance(a, b)
+}
+
+// ClosestPoints returns a point of p and a point of q at minimum distance from
+// each other. If the polygons intersect, both are the same common point.
+// If either polygon is empty, both are the zero Point.
+func (p Polygon) ClosestPoints(q Polygon) (Point, Point) {
+	return closestPoints(p, q, p.segments(), q.segments())
+}
+
+// closestPoints implements ClosestPoints, given the segments of p and q.
+func closestPoints(p, q Polygon, ps, qs []segment) (Point, Point) {
+	a, b := closestSegmentPoints(ps, qs)
+	if !a.Equals(b) {
+		// The boundaries are disjoint, so q either lies within p, or the other way round,
+		// or the polygons are disjoint, which a single vertex of each decides.
+		if v := qs[0].start; p.contains(v) {
+			return v, v
+		}
+		if v := ps[0].start; q.contains(v) {
+			return v, v
+		}
+	}
+	return a, b
+}
+
+// ClosestPoint returns the point on the boundary of p closest to pt,
+// for example to snap a cursor position to the polygon outline.
+// If p is empty, pt itself is returned.
+func (p Polygon) ClosestPoint(pt Point) Point {
+	best, bestDist := pt, math.Inf(1)
+	for _, s := range p.segments() {
+		if rectDistance(pt, s.bounds()) >= bestDist {
+			continue
+		}
+		if c := s.closestPoint(pt); distance(c, pt) < bestDist {
+			best, bestDist = c, distance(c, pt)
+		}
+	}
+	return best
+}
+
+// PolylineDistance returns the minimum distance between the open polylines a and b.
+func PolylineDistance(a, b Contour) float64 {
+	pa, pb := PolylineClosestPoints(a, b)
+	return distance(pa, pb)
+}
+
+// PolylineClosestPoints returns a point of a and a point of b at minimum distance
+// from each other, where a and b are open polylines such as those returned by
+// Construct with CLIPLINE.
+func PolylineClosestPoints(a, b Contour) (Point, Point) {
+	return closestSegmentPoints(a.polyline(), b.polyline())
+}
+
+// HausdorffDistance returns the Hausdorff distance between the boundaries of a
+// and b: the largest distance from a point on the boundary of either polygon
+// to the nearest point on the boundary of the other. It is +Inf if either
+// polygon is empty.
+func HausdorffDistance(a, b Polygon) float64 {
+	as, bs := a.segments(), b.segments()
+	return math.Max(directedHausdorff(as, bs), directedHausdorff(bs, as))
+}
+
+// FrechetDistance returns the discrete Fréchet distance between the polylines a
+// and b: the shortest leash allowing to walk both vertex sequences in order,
+// each walker either staying or advancing by one vertex at each step.
+// Unlike HausdorffDistance, it takes the direction of the outlines into account.
+func FrechetDistance(a, b Contour) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return math.Inf(1)
+	}
+	// Dynamic programming over the coupling of a[:i+1] and b[:j+1], row by row.
+	prev, row := make([]float64, len(b)), make([]float64, len(b))
+	for i := range a {
+		for j := range b {
+			d := distance(a[i], b[j])
+			switch {
+			case i == 0 && j == 0:
+				row[j] = d
+			case i == 0:
+				row[j] = math.Max(row[j-1], d)
+			case j == 0:
+				row[j] = math.Max(prev[j], d)
+			default:
+				row[j] = math.Max(math.Min(prev[j], math.Min(prev[j-1], row[j-1])), d)
+			}
+		}
+		prev, row = row, prev
+	}
+	return prev[len(b)-1]
+}
+
+// directedHausdorff returns the largest distance from a point of the segments
+// a to the nearest of the segments b.
+func directedHausdorff(a, b []segment) float64 {
+	if len(b) == 0 {
+		return math.Inf(1)
+	}
+	sortSegments(b)
+	max := 0.
+	for _, s := range a {
+		max = farthestDistance(s, b, max)
+	}
+	return max
+}
+
+// farthestDistance returns the largest distance from a point of s to the
+// nearest of segs, which are sorted by their leftmost points, if it exceeds
+// floor, and floor otherwise.
+//
+// Along s, the distance to each of segs is convex, so the distance to the
+// nearest one is largest at an end of s or where the nearest one changes, at a
+// point equally far from two of them. Rather than comparing all pairs, s is
+// divided into pieces: along a piece, the distance to the segment nearest to
+// its start is at most that at either end, which bounds the maximum. Pieces
+// where that bound does not exceed floor are skipped; otherwise only the
+// segments within the bound of the piece can be nearest along it.
+func farthestDistance(s segment, segs []segment, floor float64) float64 {
+	at := func(t float64) Point {
+		return Point{s.start.X + t*(s.end.X-s.start.X), s.start.Y + t*(s.end.Y-s.start.Y)}
+	}
+	max := floor
+	pieces := [][2]float64{{0, 1}}
+	for len(pieces) > 0 {
+		t0, t1 := pieces[len(pieces)-1][0], pieces[len(pieces)-1][1]
+		pieces = pieces[:len(pieces)-1]
+		piece := segment{at(t0), at(t1)}
+		nearest, d0 := nearestSegment(piece.start, segs)
+		max = math.Max(max, d0)
+		bound := math.Max(d0, distance(piece.end, nearest.closestPoint(piece.end)))
+		if bound <= max {
+			continue
+		}
+		r := piece.bounds()
+		var near []segment
+		for _, o := range segs {
+			if o.bounds().Min.X-r.Max.X > bound {
+				break // segments are sorted by their leftmost point
+			}
+			if rectGap(r, o.bounds()) > bound {
+				continue
+			}
+			if a, b := piece.closestPoints(o); distance(a, b) <= bound {
+				near = append(near, o)
+			}
+		}
+		// Comparing all pairs takes quadratic time, so divide pieces near
+		// many segments further, down to a limit.
+		if len(near) > 16 && t1-t0 > 1./1024 {
+			mid := t0 + (t1-t0)/2
+			pieces = append(pieces, [2]float64{t0, mid}, [2]float64{mid, t1})
+			continue
+		}
+		max = math.Max(max, farthestAlong(piece, near))
+	}
+	return max
+}
+
+// nearestSegment returns the one of segs, sorted by their leftmost points,
+// nearest to p, and its distance from p.
+func nearestSegment(p Point, segs []segment) (segment, float64) {
+	var nearest segment
+	dist := math.Inf(1)
+	for _, s := range segs {
+		if s.bounds().Min.X-p.X >= dist {
+			break
+		}
+		if rectDistance(p, s.bounds()) >= dist {
+			continue
+		}
+		if d := distance(p, s.closestPoint(p)); d < dist {
+			nearest, dist = s, d
+		}
+	}
+	return nearest, dist
+}
+
+// farthestAlong returns the largest distance from a point of s to the nearest
+// of segs, comparing the distances at the ends of s and at all points of s
+// equally far from two of segs.
+func farthestAlong(s segment, segs []segment) float64 {
+	ts := []float64{0, 1}
+	dists := make([]sqrDistance, len(segs))
+	for i, o := range segs {
+		dists[i] = newSqrDistance(s, o)
+	}
+	for i := range dists {
+		for j := i + 1; j < len(dists); j++ {
+			ts = dists[i].equal(dists[j], ts)
+		}
+	}
+	max := 0.
+	for _, t := range ts {
+		p := Point{s.start.X + t*(s.end.X-s.start.X), s.start.Y + t*(s.end.Y-s.start.Y)}
+		nearest := math.Inf(1)
+		for _, o := range segs {
+			nearest = math.Min(nearest, distance(p, o.closestPoint(p)))
+		}
+		max = math.Max(max, nearest)
+	}
+	return max
+}
+
+// sqrDistance is the squared distance from the point s.start+t*(s.end-s.start)
+// of a segment s to another segment, as a function of t. It is quadratic in t
+// on each of three intervals: where the nearest point is the other segment's
+// start, where it lies in between, and where it is its end. They are divided
+// at breaks, and the quadratics are given by their coefficients of t², t and 1.
+type sqrDistance struct {
+	breaks [2]float64
+	quads  [3][3]float64
+}
+
+func newSqrDistance(s, o segment) sqrDistance {
+	v := Point{s.end.X - s.start.X, s.end.Y - s.start.Y}
+	w := Point{o.end.X - o.start.X, o.end.Y - o.start.Y}
+	// The squared distance from s.start+t*v to the point q.
+	toPoint := func(q Point) [3]float64 {
+		e := Point{s.start.X - q.X, s.start.Y - q.Y}
+		return [3]float64{v.X*v.X + v.Y*v.Y, 2 * (e.X*v.X + e.Y*v.Y), e.X*e.X + e.Y*e.Y}
+	}
+	ww := w.X*w.X + w.Y*w.Y
+	d := sqrDistance{breaks: [2]float64{math.Inf(1), math.Inf(1)}}
+	d.quads[0], d.quads[2] = toPoint(o.start), toPoint(o.end)
+	if ww == 0 {
+		d.quads[1] = d.quads[0]
+		return d
+	}
+	// The position of the projection onto o, u0+t*du, where 0 and 1 are its ends.
+	e := Point{s.start.X - o.start.X, s.start.Y - o.start.Y}
+	u0, du := (e.X*w.X+e.Y*w.Y)/ww, (v.X*w.X+v.Y*w.Y)/ww
+	// In between, the squared distance from the line through o.
+	c0, dc := w.X*e.Y-w.Y*e.X, w.X*v.Y-w.Y*v.X
+	d.quads[1] = [3]float64{dc * dc / ww, 2 * c0 * dc / ww, c0 * c0 / ww}
+	switch {
+	case du > 0:
+		d.breaks = [2]float64{-u0 / du, (1 - u0) / du}
+	case du < 0:
+		d.quads[0], d.quads[2] = d.quads[2], d.quads[0]
+		d.breaks = [2]float64{(1 - u0) / du, -u0 / du}
+	case u0 < 0:
+		d.breaks = [2]float64{math.Inf(1), math.Inf(1)}
+	case u0 > 1:
+		d.breaks = [2]float64{math.Inf(-1), math.Inf(-1)}
+	default:
+		d.breaks = [2]float64{math.Inf(-1), math.Inf(1)}
+	}
+	return d
+}
+
+// at returns the quadratic for the interval containing t.
+func (d sqrDistance) at(t float64) [3]float64 {
+	switch {
+	case t < d.breaks[0]:
+		return d.quads[0]
+	case t <= d.breaks[1]:
+		return d.quads[1]
+	}
+	return d.quads[2]
+}
+
+// equal appends to ts the values of t between 0 and 1 at which d and o are equal.
+func (d sqrDistance) equal(o sqrDistance, ts []float64) []float64 {
+	bounds := []float64{0, 1}
+	for _, b := range []float64{d.breaks[0], d.breaks[1], o.breaks[0], o.breaks[1]} {
+		if b > 0 && b < 1 {
+			bounds = append(bounds, b)
+		}
+	}
+	sort.Float64s(bounds)
+	for i := 0; i+1 < len(bounds); i++ {
+		lo, hi := bounds[i], bounds[i+1]
+		mid := lo + (hi-lo)/2
+		q, r := d.at(mid), o.at(mid)
+		for _, t := range quadraticRoots(q[0]-r[0], q[1]-r[1], q[2]-r[2]) {
+			if t >= lo && t <= hi {
+				ts = append(ts, t)
+			}
+		}
+	}
+	return ts
+}
+
+// quadraticRoots returns the real roots of a*t² + b*t + c.
+func quadraticRoots(a, b, c float64) []float64 {
+	if a == 0 {
+		if b == 0 {
+			return nil
+		}
+		return []float64{-c / b}
+	}
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		return nil
+	}
+	// Avoid cancellation between -b and the root of disc.
+	q := -(b + math.Copysign(math.Sqrt(disc), b)) / 2
+	if q == 0 {
+		return []float64{0}
+	}
+	return []float64{q / a, c / q}
+}
+
+// closestSegmentPoints returns the closest pair of points on a and b.
+// It sweeps both from left to right, sorted by their leftmost points. A
+// segment of b becomes active once it starts within the current minimum
+// distance of the right end of the current segment of a, and is dropped for
+// good once it ends farther than that to the left of its left end, as the
+// following segments of a start no farther left and the minimum only shrinks.
+// Active segments are compared by their bounding boxes first.
+func closestSegmentPoints(a, b []segment) (Point, Point) {
+	sortSegments(a)
+	sortSegments(b)
+	var pa, pb Point
+	best := math.Inf(1)
+	var active []segment
+	next := 0
+	for _, sa := range a {
+		ra := sa.bounds()
+		for ; next < len(b) && b[next].bounds().Min.X-ra.Max.X < best; next++ {
+			active = append(active, b[next])
+		}
+		kept := active[:0]
+		for _, sb := range active {
+			rb := sb.bounds()
+			if ra.Min.X-rb.Max.X >= best {
+				continue
+			}
+			kept = append(kept, sb)
+			if rectGap(ra, rb) >= best {
+				continue
+			}
+			if ca, cb := sa.closestPoints(sb); distance(ca, cb) < best {
+				pa, pb, best = ca, cb, distance(ca, cb)
+				if best == 0 {
+					return pa, pb
+				}
+			}
+		}
+		active = kept
+	}
+	return pa, pb
+}
+
+// segments returns the edges of all contours of p.
+func (p Polygon) segments() []segment {
+	var segs []segment
+	for _, c := range p {
+		for i := range c {
+			segs = append(segs, c.segment(i))
+		}
+	}
+	return segs
+}
+
+// polyline returns the edges of c, treated as an open polyline. A single
+// point makes up a zero-length segment.
+func (c Contour) polyline() []segment {
+	if len(c) == 1 {
+		return []segment{{c[0], c[0]}}
+	}
+	segs := make([]segment, 0, len(c))
+	for i := 0; i+1 < len(c); i++ {
+		segs = append(segs, c.segment(i))
+	}
+	return segs
+}
+
+// contains returns whether pt lies in the interior of p, counting holes
+// by the even-odd rule.
+func (p Polygon) contains(pt Point) bool {
+	inside := false
+	for _, c := range p {
+		if c.Contains(pt) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func sortSegments(segs []segment) {
+	sort.Slice(segs, func(i, j int) bool {
+		return segs[i].bounds().Min.X < segs[j].bounds().Min.X
+	})
+}
+
+func (s segment) bounds() Rectangle {
+	return Rectangle{
+		Min: Point{math.Min(s.start.X, s.end.X), math.Min(s.start.Y, s.end.Y)},
+		Max: Point{math.Max(s.start.X, s.end.X), math.Max(s.start.Y, s.end.Y)},
+	}
+}
+
+// closestPoint returns the point of s closest to p.
+func (s segment) closestPoint(p Point) Point {
+	dx, dy := s.end.X-s.start.X, s.end.Y-s.start.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return s.start
+	}
+	t := ((p.X-s.start.X)*dx + (p.Y-s.start.Y)*dy) / l2
+	switch {
+	case t <= 0:
+		return s.start
+	case t >= 1:
+		return s.end
+	}
+	return Point{s.start.X + t*dx, s.start.Y + t*dy}
+}
+
+// closestPoints returns a point of s and a point of o at minimum distance.
+func (s segment) closestPoints(o segment) (Point, Point) {
+	if p, ok := s.crossing(o); ok {
+		return p, p
+	}
+	// Otherwise one of the closest points is an endpoint.
+	candidates := [4][2]Point{
+		{s.start, o.closestPoint(s.start)},
+		{s.end, o.closestPoint(s.end)},
+		{s.closestPoint(o.start), o.start},
+		{s.closestPoint(o.end), o.end},
+	}
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if distance(c[0], c[1]) < distance(best[0], best[1]) {
+			best = c
+		}
+	}
+	return best[0], best[1]
+}
+
+// crossing returns the point where s and o properly cross, if any.
+func (s segment) crossing(o segment) (Point, bool) {
+	d1 := signedArea(o.start, o.end, s.start)
+	d2 := signedArea(o.start, o.end, s.end)
+	d3 := signedArea(s.start, s.end, o.start)
+	d4 := signedArea(s.start, s.end, o.end)
+	if d1*d2 >= 0 || d3*d4 >= 0 {
+		return Point{}, false
+	}
+	t := d1 / (d1 - d2)
+	return Point{s.start.X + t*(s.end.X-s.start.X), s.start.Y + t*(s.end.Y-s.start.Y)}, true
+}
+
+func distance(a, b Point) float64 {
+	return Point{a.X - b.X, a.Y - b.Y}.Length()
+}
+
+// rectDistance returns the distance from p to the nearest point of r.
+func rectDistance(p Point, r Rectangle) float64 {
+	return rectGap(Rectangle{p, p}, r)
+}
+
+// rectGap returns the distance between the nearest points of r1 and r2.
+func rectGap(r1, r2 Rectangle) float64 {
+	dx := math.Max(0, math.Max(r1.Min.X-r2.Max.X, r2.Min.X-r1.Max.X))
+	dy := math.Max(0, math.Max(r1.Min.Y-r2.Max.Y, r2.Min.Y-r1.Max.Y))
+	return math.Hypot(dx, dy)
+}
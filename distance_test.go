@@ -0,0 +1,166 @@
+package polyclip
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestPolygonDistance(t *testing.T) {
+	square := func(x, y, size float64) Polygon {
+		return Polygon{{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}}
+	}
+	withHole := Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, {{2, 2}, {8, 2}, {8, 8}, {2, 8}}}
+	cases := []struct {
+		name string
+		a, b Polygon
+		dist float64
+	}{
+		{"side by side", square(0, 0, 1), square(3, 0, 1), 2},
+		{"diagonal", square(0, 0, 1), square(4, 5, 1), 5},
+		{"vertex to edge", square(0, 0, 2), Polygon{{{1, 3}, {2, 5}, {0, 5}}}, 1},
+		{"crossing", square(0, 0, 2), square(1, 1, 2), 0},
+		{"inside", square(0, 0, 4), square(1, 1, 1), 0},
+		{"in hole", withHole, square(4, 4, 1), 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			verify(t, circa(c.a.Distance(c.b), c.dist), "expected distance %g, got %g", c.dist, c.a.Distance(c.b))
+			verify(t, circa(c.b.Distance(c.a), c.dist), "expected symmetric distance %g, got %g", c.dist, c.b.Distance(c.a))
+			pa, pb := c.a.ClosestPoints(c.b)
+			verify(t, circa(distance(pa, pb), c.dist), "expected closest points at distance %g, got %v %v", c.dist, pa, pb)
+			if c.dist > 0 {
+				verify(t, c.a.ClosestPoint(pa).Equals(pa), "expected %v on the boundary of %v", pa, c.a)
+				verify(t, c.b.ClosestPoint(pb).Equals(pb), "expected %v on the boundary of %v", pb, c.b)
+			}
+		})
+	}
+	verify(t, math.IsInf(square(0, 0, 1).Distance(nil), 1), "expected infinite distance to empty polygon")
+}
+
+func TestClosestPoint(t *testing.T) {
+	p := Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}}
+	cases := []struct{ pt, want Point }{
+		{Point{2, -3}, Point{2, 0}},
+		{Point{6, 6}, Point{4, 4}},
+		{Point{1, 2}, Point{0, 2}},
+		{Point{4, 1}, Point{4, 1}},
+	}
+	for _, c := range cases {
+		got := p.ClosestPoint(c.pt)
+		verify(t, got.Equals(c.want), "closest point to %v: expected %v, got %v", c.pt, c.want, got)
+	}
+}
+
+func TestPolylineDistance(t *testing.T) {
+	a := Contour{{0, 0}, {2, 0}, {2, 2}}
+	verify(t, circa(PolylineDistance(a, Contour{{3, 1}, {5, 1}}), 1), "expected distance 1")
+	verify(t, circa(PolylineDistance(a, Contour{{0, 2}}), 2), "expected distance 2 to single point")
+	// The closing edge of a is not part of the polyline.
+	verify(t, circa(PolylineDistance(a, Contour{{0.5, 1}, {0.5, 1.5}}), 1), "expected distance 1 without closing edge")
+	pa, pb := PolylineClosestPoints(a, Contour{{1, -1}, {1, 1}})
+	verify(t, pa.Equals(Point{1, 0}) && pb.Equals(Point{1, 0}), "expected crossing point, got %v %v", pa, pb)
+}
+
+func TestHausdorffDistance(t *testing.T) {
+	a := Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}}
+	verify(t, HausdorffDistance(a, a) == 0, "expected 0 for identical polygons")
+	// Extra vertices along the edges do not change the outline.
+	b := Polygon{{{0, 0}, {2, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 2}}}
+	verify(t, HausdorffDistance(a, b) == 0, "expected 0 for the same outline")
+	c := Polygon{{{0, 0}, {4, 0}, {4, 4}, {2, 7}, {0, 4}}}
+	verify(t, circa(HausdorffDistance(a, c), 3), "expected 3, got %g", HausdorffDistance(a, c))
+	verify(t, circa(HausdorffDistance(c, a), 3), "expected symmetric distance 3")
+	// The centre of the bowtie is 2 away from the square, although every
+	// vertex of either lies on the other.
+	bowtie := Polygon{{{0, 0}, {4, 4}, {4, 0}, {0, 4}}}
+	verify(t, circa(HausdorffDistance(a, bowtie), 2), "expected 2 for a bowtie on the corners, got %g", HausdorffDistance(a, bowtie))
+	// The vertex {5, 3} of e is the farthest point from f; the closing edges
+	// of e and f coincide.
+	e := Polygon{{{0, 0}, {5, 3}, {10, 0}, {10, 1}}}
+	f := Polygon{{{0, 0}, {10, 0}, {10, 1}}}
+	verify(t, circa(HausdorffDistance(e, f), 25/math.Sqrt(101)), "expected %g, got %g", 25/math.Sqrt(101), HausdorffDistance(e, f))
+}
+
+func TestDirectedHausdorff(t *testing.T) {
+	// The farthest point of f from the open polyline e is {5, 0}, equally
+	// far from the two edges of e meeting at {5, 3}, rather than a vertex.
+	e := Contour{{0, 0}, {5, 3}, {10, 0}, {10, 1}}.polyline()
+	f := Contour{{0, 0}, {10, 0}, {10, 1}}.polyline()
+	want := 15 / math.Sqrt(34)
+	verify(t, circa(directedHausdorff(f, e), want), "expected %g, got %g", want, directedHausdorff(f, e))
+	verify(t, circa(directedHausdorff(e, f), 3), "expected 3, got %g", directedHausdorff(e, f))
+	verify(t, math.IsInf(directedHausdorff(f, nil), 1), "expected +Inf to no segments")
+}
+
+func TestHausdorffDistanceRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randomPolygon := func(n int) Polygon {
+		c := make(Contour, n)
+		for i := range c {
+			c[i] = Point{rnd.Float64() * 10, rnd.Float64() * 10}
+		}
+		return Polygon{c}
+	}
+	for i := 0; i < 50; i++ {
+		a, b := randomPolygon(3+rnd.Intn(8)), randomPolygon(3+rnd.Intn(8))
+		want := sampledHausdorff(a, b, 2000)
+		got := HausdorffDistance(a, b)
+		// Sampling finds the maximum within the spacing of the samples.
+		verify(t, got >= want-1e-9 && got < want+0.02, "%v, %v: expected about %g, got %g", a, b, want, got)
+	}
+}
+
+// sampledHausdorff approximates the Hausdorff distance between the boundaries
+// of a and b from n points along each edge.
+func sampledHausdorff(a, b Polygon, n int) float64 {
+	directed := func(a, b Polygon) float64 {
+		max := 0.
+		for _, s := range a.segments() {
+			for i := 0; i <= n; i++ {
+				t := float64(i) / float64(n)
+				p := Point{s.start.X + t*(s.end.X-s.start.X), s.start.Y + t*(s.end.Y-s.start.Y)}
+				max = math.Max(max, distance(p, b.ClosestPoint(p)))
+			}
+		}
+		return max
+	}
+	return math.Max(directed(a, b), directed(b, a))
+}
+
+func TestClosestSegmentPoints(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randomSegments := func(n int, length float64) []segment {
+		segs := make([]segment, n)
+		for i := range segs {
+			p := Point{rnd.Float64() * 100, rnd.Float64() * 100}
+			l := rnd.Float64() * length
+			segs[i] = segment{p, Point{p.X + (rnd.Float64()-0.5)*l, p.Y + (rnd.Float64()-0.5)*l}}
+		}
+		return segs
+	}
+	for i := 0; i < 200; i++ {
+		// Mix short segments with long ones spanning many others.
+		a := append(randomSegments(20, 5), randomSegments(2, 200)...)
+		b := append(randomSegments(20, 5), randomSegments(2, 200)...)
+		want := math.Inf(1)
+		for _, sa := range a {
+			for _, sb := range b {
+				ca, cb := sa.closestPoints(sb)
+				want = math.Min(want, distance(ca, cb))
+			}
+		}
+		pa, pb := closestSegmentPoints(a, b)
+		verify(t, distance(pa, pb) == want, "case %d: expected distance %g, got %g", i, want, distance(pa, pb))
+	}
+}
+
+func TestFrechetDistance(t *testing.T) {
+	a := Contour{{0, 0}, {1, 0}, {2, 0}, {3, 0}}
+	verify(t, FrechetDistance(a, a) == 0, "expected 0 for identical polylines")
+	b := Contour{{0, 1}, {3, 1}}
+	verify(t, circa(FrechetDistance(a, b), math.Sqrt2), "expected √2, got %g", FrechetDistance(a, b))
+	// Reversing a polyline keeps its Hausdorff distance, but not its Fréchet distance.
+	reversed := Contour{{3, 0}, {2, 0}, {1, 0}, {0, 0}}
+	verify(t, circa(FrechetDistance(a, reversed), 3), "expected 3, got %g", FrechetDistance(a, reversed))
+}
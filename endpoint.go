@@ -40,6 +40,9 @@ type endpoint struct {
 	inout bool
 	edgeType
 	inside bool // Only used in "left" events. Is the segment (p, other->p) inside the other polygon?
+
+	// Index of the input contour and edge this segment was derived from
+	contour, edge int
 }
 
 func (e endpoint) String() string {
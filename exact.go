@@ -0,0 +1,393 @@
+package polyclip
+
+import (
+	"math/big"
+	"sort"
+)
+
+// ConstructExact computes the same result as Construct, but using exact rational
+// arithmetic (math/big.Rat) for all intersections and orientation tests, so it
+// is not affected by rounding errors. Only the vertices of the result are
+// rounded to the nearest float64 values.
+//
+// It divides the edges of both polygons at all their intersections, by testing
+// every pair of edges, and classifies each resulting piece by casting a ray from
+// its midpoint, so it takes time O(n^2) for n edges, and is also much slower
+// than Construct by a constant factor. It is meant as a reference to compare
+// Construct against, and as a fallback for inputs Construct fails on.
+// Overlapping parts of the contours of a polygon cancel out, following the
+// even-odd rule.
+func (p Polygon) ConstructExact(operation Op, clipping Polygon) Polygon {
+	var in func(a, b bool) bool
+	switch operation {
+	case INTERSECTION:
+		in = func(a, b bool) bool { return a && b }
+	case UNION:
+		in = func(a, b bool) bool { return a || b }
+	case DIFFERENCE:
+		in = func(a, b bool) bool { return a && !b }
+	case XOR:
+		in = func(a, b bool) bool { return a != b }
+	case CLIPLINE:
+		return p.clipLineExact(clipping)
+	default:
+		return Polygon{}
+	}
+	arr := newArrangement(exactEdges(p, false), exactEdges(clipping, false))
+	var result []ratSegment
+	for _, pc := range arr.pieces {
+		a1, a2 := arr.sides(pc, 0)
+		b1, b2 := arr.sides(pc, 1)
+		if in(a1, b1) != in(a2, b2) {
+			result = append(result, pc.oriented(in(a1, b1)))
+		}
+	}
+	return chainBoundary(result)
+}
+
+// SimplifyExact removes self-intersections and repeated edges from p like
+// Simplify, using exact rational arithmetic; see ConstructExact. Parts of the
+// plane covered by an odd number of contours make up the result.
+func (p Polygon) SimplifyExact() Polygon {
+	arr := newArrangement(exactEdges(p, false), nil)
+	var result []ratSegment
+	for _, pc := range arr.pieces {
+		if pc.count[0]%2 == 1 {
+			in, _ := arr.sides(pc, 0)
+			result = append(result, pc.oriented(in))
+		}
+	}
+	return chainBoundary(result)
+}
+
+// clipLineExact returns the parts of the line strings in p that lie within
+// clipping or on its boundary.
+func (p Polygon) clipLineExact(clipping Polygon) Polygon {
+	arr := newArrangement(exactEdges(p, true), exactEdges(clipping, false))
+	var result []ratSegment
+	for _, pc := range arr.pieces {
+		if pc.count[0] == 0 {
+			continue
+		}
+		b1, b2 := arr.sides(pc, 1)
+		if b1 || b2 {
+			result = append(result, ratSegment{pc.a, pc.b})
+		}
+	}
+	return chainLines(result)
+}
+
+// ratPoint is a point with rational coordinates.
+type ratPoint struct {
+	x, y *big.Rat
+}
+
+func newRatPoint(p Point) ratPoint {
+	return ratPoint{new(big.Rat).SetFloat64(p.X), new(big.Rat).SetFloat64(p.Y)}
+}
+
+func (p ratPoint) key() string {
+	return p.x.RatString() + "," + p.y.RatString()
+}
+
+func (p ratPoint) less(q ratPoint) bool {
+	if c := p.x.Cmp(q.x); c != 0 {
+		return c < 0
+	}
+	return p.y.Cmp(q.y) < 0
+}
+
+func (p ratPoint) equals(q ratPoint) bool {
+	return p.x.Cmp(q.x) == 0 && p.y.Cmp(q.y) == 0
+}
+
+func (p ratPoint) float() Point {
+	x, _ := p.x.Float64()
+	y, _ := p.y.Float64()
+	return Point{x, y}
+}
+
+func ratSub(a, b *big.Rat) *big.Rat { return new(big.Rat).Sub(a, b) }
+func ratMul(a, b *big.Rat) *big.Rat { return new(big.Rat).Mul(a, b) }
+
+// ratCross returns the cross product of b-a and c-a, which is positive if
+// a, b and c are in counter-clockwise order.
+func ratCross(a, b, c ratPoint) *big.Rat {
+	return ratSub(ratMul(ratSub(b.x, a.x), ratSub(c.y, a.y)), ratMul(ratSub(b.y, a.y), ratSub(c.x, a.x)))
+}
+
+// exactEdge is an input edge of polygon 0 or 1.
+type exactEdge struct {
+	a, b    ratPoint
+	polygon int
+}
+
+// exactEdges returns the edges of p, leaving out the closing edge of each
+// contour if lines is set. The polygon index is filled in by newArrangement.
+func exactEdges(p Polygon, lines bool) []exactEdge {
+	var edges []exactEdge
+	for _, c := range p {
+		for i := range c {
+			if lines && i == len(c)-1 {
+				break
+			}
+			s := c.segment(i)
+			if !s.start.Equals(s.end) {
+				edges = append(edges, exactEdge{a: newRatPoint(s.start), b: newRatPoint(s.end)})
+			}
+		}
+	}
+	return edges
+}
+
+// exactPiece is a part of one or more input edges between consecutive
+// intersections, from its lesser to its greater endpoint.
+type exactPiece struct {
+	a, b  ratPoint
+	count [2]int // number of edges of each polygon containing the piece
+}
+
+// arrangement holds the input edges of two polygons divided into pieces that
+// meet only at their endpoints.
+type arrangement struct {
+	edges  [2][]exactEdge
+	pieces []*exactPiece
+}
+
+func newArrangement(edges0, edges1 []exactEdge) *arrangement {
+	arr := &arrangement{edges: [2][]exactEdge{edges0, edges1}}
+	var all []exactEdge
+	for i, edges := range arr.edges {
+		for j := range edges {
+			edges[j].polygon = i
+			all = append(all, edges[j])
+		}
+	}
+
+	// Collect the points at which each edge has to be divided.
+	splits := make([][]ratPoint, len(all))
+	for i := range all {
+		splits[i] = []ratPoint{all[i].a, all[i].b}
+	}
+	for i := range all {
+		for j := i + 1; j < len(all); j++ {
+			for _, p := range intersectExact(all[i], all[j]) {
+				splits[i] = append(splits[i], p)
+				splits[j] = append(splits[j], p)
+			}
+		}
+	}
+
+	byKey := make(map[string]*exactPiece)
+	for i, e := range all {
+		pts := splits[i]
+		sort.Slice(pts, func(k, l int) bool { return pts[k].less(pts[l]) })
+		for k := 0; k+1 < len(pts); k++ {
+			a, b := pts[k], pts[k+1]
+			if a.equals(b) {
+				continue
+			}
+			key := a.key() + ";" + b.key()
+			pc, ok := byKey[key]
+			if !ok {
+				pc = &exactPiece{a: a, b: b}
+				byKey[key] = pc
+				arr.pieces = append(arr.pieces, pc)
+			}
+			pc.count[e.polygon]++
+		}
+	}
+	return arr
+}
+
+// intersectExact returns the points where e and f meet: their crossing point,
+// or the endpoints of each lying on the other.
+func intersectExact(e, f exactEdge) []ratPoint {
+	d1, d2 := ratCross(f.a, f.b, e.a), ratCross(f.a, f.b, e.b)
+	d3, d4 := ratCross(e.a, e.b, f.a), ratCross(e.a, e.b, f.b)
+	if d1.Sign()*d2.Sign() < 0 && d3.Sign()*d4.Sign() < 0 {
+		// A proper crossing, at e.a + t*(e.b - e.a) with t = d1/(d1-d2).
+		t := new(big.Rat).Quo(d1, ratSub(d1, d2))
+		return []ratPoint{{
+			new(big.Rat).Add(e.a.x, ratMul(t, ratSub(e.b.x, e.a.x))),
+			new(big.Rat).Add(e.a.y, ratMul(t, ratSub(e.b.y, e.a.y))),
+		}}
+	}
+	var points []ratPoint
+	if d1.Sign() == 0 && onExactEdge(e.a, f) {
+		points = append(points, e.a)
+	}
+	if d2.Sign() == 0 && onExactEdge(e.b, f) {
+		points = append(points, e.b)
+	}
+	if d3.Sign() == 0 && onExactEdge(f.a, e) {
+		points = append(points, f.a)
+	}
+	if d4.Sign() == 0 && onExactEdge(f.b, e) {
+		points = append(points, f.b)
+	}
+	return points
+}
+
+// onExactEdge returns whether p, known to be collinear with e, lies on it.
+func onExactEdge(p ratPoint, e exactEdge) bool {
+	lo, hi := e.a, e.b
+	if hi.less(lo) {
+		lo, hi = hi, lo
+	}
+	return !p.less(lo) && !hi.less(p)
+}
+
+// sides returns whether polygon i covers the parts of the plane on either side
+// of pc: right and left of it, or above and below it if it is horizontal.
+func (arr *arrangement) sides(pc *exactPiece, i int) (bool, bool) {
+	half := big.NewRat(1, 2)
+	m := ratPoint{
+		ratMul(half, new(big.Rat).Add(pc.a.x, pc.b.x)),
+		ratMul(half, new(big.Rat).Add(pc.a.y, pc.b.y)),
+	}
+	horizontal := pc.a.y.Cmp(pc.b.y) == 0
+
+	// Cast a ray from m, to the right or, for horizontal pieces, upwards, and
+	// count the edges it crosses, other than those containing pc. Vertices on
+	// the ray count as lying just beyond it.
+	inside := false
+	for _, e := range arr.edges[i] {
+		a, b, mu, mv := e.a.y, e.b.y, m.y, m.x
+		ax, bx := e.a.x, e.b.x
+		if horizontal {
+			a, b, mu, mv = e.a.x, e.b.x, m.x, m.y
+			ax, bx = e.a.y, e.b.y
+		}
+		if (a.Cmp(mu) > 0) == (b.Cmp(mu) > 0) {
+			continue
+		}
+		// The coordinate along the ray where it crosses e.
+		t := new(big.Rat).Quo(ratSub(mu, a), ratSub(b, a))
+		v := new(big.Rat).Add(ax, ratMul(t, ratSub(bx, ax)))
+		if v.Cmp(mv) > 0 {
+			inside = !inside
+		}
+	}
+	// Only edges containing pc separate its two sides.
+	if pc.count[i]%2 == 1 {
+		return inside, !inside
+	}
+	return inside, inside
+}
+
+// ratSegment is a directed segment with rational endpoints.
+type ratSegment struct {
+	a, b ratPoint
+}
+
+// oriented returns pc directed so that its first side, as returned by sides,
+// lies on its left if in is set, and on its right otherwise.
+func (pc *exactPiece) oriented(in bool) ratSegment {
+	// The first side is to the right of the piece or, if it is horizontal, above it.
+	firstLeft := pc.a.y.Cmp(pc.b.y) == 0 || pc.b.y.Cmp(pc.a.y) < 0
+	if in == firstLeft {
+		return ratSegment{pc.a, pc.b}
+	}
+	return ratSegment{pc.b, pc.a}
+}
+
+// chainBoundary links segments having the result on their left into contours.
+// Where several contours meet at a vertex, each keeps to the part of the result
+// it bounds, taking the leftmost turn, so that contours do not cross.
+func chainBoundary(segs []ratSegment) Polygon {
+	from := make(map[string][]int)
+	for i, s := range segs {
+		from[s.a.key()] = append(from[s.a.key()], i)
+	}
+	used := make([]bool, len(segs))
+	result := Polygon{}
+	for start := range segs {
+		if used[start] {
+			continue
+		}
+		var c Contour
+		for i, ok := start, true; ok; {
+			used[i] = true
+			c.Add(segs[i].a.float())
+			// Of the unused segments leaving the end of segment i, take the first
+			// one clockwise from the direction back along segment i.
+			end := segs[i].b
+			back := ratPoint{ratSub(segs[i].a.x, end.x), ratSub(segs[i].a.y, end.y)}
+			best, found := 0, false
+			for _, j := range from[end.key()] {
+				if used[j] {
+					continue
+				}
+				dir := ratPoint{ratSub(segs[j].b.x, end.x), ratSub(segs[j].b.y, end.y)}
+				if !found || clockwiseBefore(back, dir, ratPoint{ratSub(segs[best].b.x, end.x), ratSub(segs[best].b.y, end.y)}) {
+					best, found = j, true
+				}
+			}
+			i, ok = best, found
+		}
+		result.Add(c)
+	}
+	return result
+}
+
+// clockwiseBefore returns whether, turning clockwise from direction u, direction
+// v is reached before direction w.
+func clockwiseBefore(u, v, w ratPoint) bool {
+	zero := ratPoint{new(big.Rat), new(big.Rat)}
+	// Whether a direction lies in the first half turn clockwise from u.
+	firstHalf := func(d ratPoint) bool {
+		c := ratCross(zero, u, d).Sign()
+		return c < 0 || c == 0 && new(big.Rat).Add(ratMul(u.x, d.x), ratMul(u.y, d.y)).Sign() > 0
+	}
+	if hv, hw := firstHalf(v), firstHalf(w); hv != hw {
+		return hv
+	}
+	return ratCross(zero, v, w).Sign() < 0
+}
+
+// chainLines links segments into line strings, starting at their ends.
+func chainLines(segs []ratSegment) Polygon {
+	at := make(map[string][]int)
+	for i, s := range segs {
+		at[s.a.key()] = append(at[s.a.key()], i)
+		at[s.b.key()] = append(at[s.b.key()], i)
+	}
+	used := make([]bool, len(segs))
+	walk := func(start int, from ratPoint) Contour {
+		c := Contour{from.float()}
+		for i, ok := start, true; ok; {
+			used[i] = true
+			if segs[i].a.equals(from) {
+				from = segs[i].b
+			} else {
+				from = segs[i].a
+			}
+			c.Add(from.float())
+			ok = false
+			for _, j := range at[from.key()] {
+				if !used[j] {
+					i, ok = j, true
+					break
+				}
+			}
+		}
+		return c
+	}
+
+	result := Polygon{}
+	for i, s := range segs {
+		for _, end := range []ratPoint{s.a, s.b} {
+			if !used[i] && len(at[end.key()])%2 == 1 {
+				result.Add(walk(i, end))
+			}
+		}
+	}
+	// Whatever is left forms closed rings; these repeat their first point.
+	for i, s := range segs {
+		if !used[i] {
+			result.Add(walk(i, s.a))
+		}
+	}
+	return result
+}
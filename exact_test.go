@@ -0,0 +1,82 @@
+package polyclip
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConstructExact(t *testing.T) {
+	square := func(x, y, size float64) Polygon {
+		return Polygon{{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}}
+	}
+	// Areas of the union, intersection, difference and xor.
+	cases := []struct {
+		name         string
+		a, b         Polygon
+		u, i, d, xor float64
+	}{
+		{"overlapping", square(0, 0, 2), square(1, 1, 2), 7, 1, 3, 6},
+		{"disjoint", square(0, 0, 1), square(5, 5, 1), 2, 0, 1, 2},
+		{"contained", square(0, 0, 4), square(1, 1, 1), 16, 1, 15, 15},
+		{"shared edge", square(0, 0, 1), square(1, 0, 1), 2, 0, 1, 2},
+		{"partly shared edge", square(0, 0, 2), square(2, 1, 2), 8, 0, 4, 8},
+		{"identical", square(0, 0, 1), square(0, 0, 1), 1, 1, 0, 0},
+		{"hole", Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}, {{1, 2.5}, {3, 2.5}, {3, 3.5}, {1, 3.5}}}, square(2, 2, 4), 27, 3, 11, 24},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for _, op := range []struct {
+				op   Op
+				area float64
+			}{{UNION, c.u}, {INTERSECTION, c.i}, {DIFFERENCE, c.d}, {XOR, c.xor}} {
+				got := c.a.ConstructExact(op.op, c.b)
+				verify(t, circa(area(got), op.area), "%v: expected area %g, got %g (%v)", op.op, op.area, area(got), got)
+			}
+		})
+	}
+}
+
+func TestConstructExactAgainstConstruct(t *testing.T) {
+	star := func(cx, cy, r float64, n int) Polygon {
+		var c Contour
+		for i := 0; i < 2*n; i++ {
+			radius := r
+			if i%2 == 1 {
+				radius = r / 2
+			}
+			angle := math.Pi * float64(i) / float64(n)
+			c.Add(Point{cx + radius*math.Cos(angle), cy + radius*math.Sin(angle)})
+		}
+		return Polygon{c}
+	}
+	a, b := star(0, 0, 10, 5), star(3, 1, 8, 7)
+	for _, op := range []Op{UNION, INTERSECTION, DIFFERENCE, XOR} {
+		want, got := area(a.Construct(op, b)), area(a.ConstructExact(op, b))
+		verify(t, math.Abs(want-got) < 1e-9, "%v: expected area %g, got %g", op, want, got)
+	}
+}
+
+func TestConstructExactClipLine(t *testing.T) {
+	line := Polygon{{{-1, 1}, {2, 1}, {2, 3}, {3, 3}}}
+	got := line.ConstructExact(CLIPLINE, Polygon{{{0, 0}, {4, 0}, {4, 2}, {0, 2}}})
+	verify(t, len(got) == 1 && len(got[0]) == 3, "expected a single line string of 3 points, got %v", got)
+	length := 0.
+	for _, c := range got {
+		for i := 0; i+1 < len(c); i++ {
+			length += distance(c[i], c[i+1])
+		}
+	}
+	verify(t, length == 3, "expected length 3, got %g (%v)", length, got)
+}
+
+func TestSimplifyExact(t *testing.T) {
+	bowtie := Polygon{{{0, 0}, {2, 2}, {2, 0}, {0, 2}}}
+	got := bowtie.SimplifyExact()
+	verify(t, area(got) == 2, "expected area 2, got %g (%v)", area(got), got)
+	for _, c := range got {
+		verify(t, len(c) == 3 || len(c) == 6, "expected triangles, or both joined at the crossing, got %v", got)
+	}
+	// Repeated edges cancel out.
+	doubled := Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, {{1, 0}, {2, 0}, {2, 1}, {1, 1}}}
+	verify(t, area(doubled.SimplifyExact()) == 2, "expected area 2, got %v", doubled.SimplifyExact())
+}
@@ -0,0 +1,337 @@
+package polyclip
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// The fuzz targets decode each polygon from bytes as a sequence of contours:
+// a byte holding the number of points, followed by the points as pairs of
+// little-endian float64 coordinates. Points with coordinates that are not
+// finite or exceed fuzzMaxCoord are left out, as are contours of fewer than
+// three points and points beyond the first fuzzMaxPoints.
+//
+// The coordinates of the operands are then rounded by snapFuzzPolygons, to
+// keep out features of a few ulps, such as vertices 1e-11 off an edge of
+// length 2 or triangles of a width of 1e-70 next to coordinates of 1e6, at
+// which the sweep gives results such as edges shared between contours.
+//
+// The seed corpus in testdata/fuzz holds the operands of the cases in
+// bugs_test.go, and inputs the fuzzer found failing. Those of a few ulps, such
+// as TestInfiniteLoopBug, are rounded as well, and are tested as they are by
+// bugs_test.go only.
+const (
+	fuzzMaxPoints = 256
+	fuzzMaxCoord  = 1e9
+	fuzzPrecision = 30 // bits of the extent of the operands kept by snapFuzzPolygons
+)
+
+func decodeFuzzPolygon(data []byte) Polygon {
+	p := Polygon{}
+	points := 0
+	for len(data) > 0 && points < fuzzMaxPoints {
+		n := int(data[0])
+		data = data[1:]
+		c := Contour{}
+		for ; n > 0 && len(data) >= 16 && points < fuzzMaxPoints; n-- {
+			pt := Point{
+				X: math.Float64frombits(binary.LittleEndian.Uint64(data)),
+				Y: math.Float64frombits(binary.LittleEndian.Uint64(data[8:])),
+			}
+			data = data[16:]
+			if !(math.Abs(pt.X) <= fuzzMaxCoord && math.Abs(pt.Y) <= fuzzMaxCoord) {
+				continue
+			}
+			c.Add(pt)
+			points++
+		}
+		if len(c) >= 3 {
+			p.Add(c)
+		}
+	}
+	return p
+}
+
+// snapFuzzPolygons rounds the coordinates of polys to multiples of a power of
+// two at most 2^-fuzzPrecision times the extent of their bounding box.
+// Coordinates that are already multiples of it are left as they are.
+func snapFuzzPolygons(polys ...Polygon) {
+	var bb *Rectangle
+	for _, p := range polys {
+		if p.NumVertices() > 0 {
+			pb := p.BoundingBox()
+			if bb != nil {
+				pb = pb.union(*bb)
+			}
+			bb = &pb
+		}
+	}
+	if bb == nil {
+		return
+	}
+	extent := math.Max(bb.Max.X-bb.Min.X, bb.Max.Y-bb.Min.Y)
+	if extent == 0 {
+		return
+	}
+	_, exp := math.Frexp(extent)
+	grid := math.Ldexp(1, exp-fuzzPrecision-1)
+	for _, p := range polys {
+		for _, c := range p {
+			for i := range c {
+				c[i] = Point{math.Round(c[i].X/grid) * grid, math.Round(c[i].Y/grid) * grid}
+			}
+		}
+	}
+}
+
+func encodeFuzzPolygon(p Polygon) []byte {
+	var data []byte
+	for _, c := range p {
+		data = append(data, byte(len(c)))
+		for _, pt := range c {
+			var b [16]byte
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(pt.X))
+			binary.LittleEndian.PutUint64(b[8:], math.Float64bits(pt.Y))
+			data = append(data, b[:]...)
+		}
+	}
+	return data
+}
+
+var errEventBudget = errors.New("event budget exceeded")
+
+// budgetTracer panics once more than budget events have been dequeued.
+type budgetTracer struct {
+	events, budget int
+}
+
+func (t *budgetTracer) Trace(e TraceEvent) {
+	if e.Kind != TRACE_DEQUEUED {
+		return
+	}
+	t.events++
+	if t.events > t.budget {
+		panic(errEventBudget)
+	}
+}
+
+// fuzzRun returns the result of op, called with options enforcing an event
+// budget fit for operands of n segments in total: each pair of segments may
+// cross once, dividing both. It fails t if op panics or exceeds the budget.
+func fuzzRun(t *testing.T, name string, n int, op func(opts ...Option) Polygon) (Polygon, Diagnostics) {
+	t.Helper()
+	var err error
+	var diag Diagnostics
+	result := op(WithRecovery(&err), WithDiagnostics(&diag), WithTracer(&budgetTracer{budget: 8 * (n + 1) * (n + 1)}))
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return result, diag
+}
+
+// selfIntersections returns the places where edges of p cross or overlap.
+// Consecutive edges retracing each other, forming a spike of no area, are
+// tolerated. Overlaps are confirmed exactly, as Intersections reports edges
+// as overlapping wherever findIntersection takes them as collinear, which
+// with its tolerance includes edges about 1e-11 apart along a length of 50.
+func selfIntersections(p Polygon) []IntersectionResult {
+	type edge struct{ contour, edge int }
+	var segs []Segment
+	var edges []edge
+	for j, c := range p {
+		for i := range c {
+			if s := c.segment(i); !s.start.Equals(s.end) {
+				segs = append(segs, s.Segment())
+				edges = append(edges, edge{j, i})
+			}
+		}
+	}
+	collinear := func(s Segment, p Point) bool {
+		return ratCross(newRatPoint(s.Start), newRatPoint(s.End), newRatPoint(p)).Sign() == 0
+	}
+	consecutive := func(a, b edge) bool {
+		n := len(p[a.contour])
+		return a.contour == b.contour && ((a.edge+1)%n == b.edge || (b.edge+1)%n == a.edge)
+	}
+	var bad []IntersectionResult
+	for _, r := range Intersections(segs) {
+		switch {
+		case r.Kind == CROSSING:
+			bad = append(bad, r)
+		case r.Kind == OVERLAP && !consecutive(edges[r.I], edges[r.J]) &&
+			collinear(segs[r.I], segs[r.J].Start) && collinear(segs[r.I], segs[r.J].End):
+			bad = append(bad, r)
+		}
+	}
+	return bad
+}
+
+// fuzzAreas returns the areas of polys, translated together to the origin to
+// avoid cancellation, along with a tolerance for comparing them.
+func fuzzAreas(polys ...Polygon) ([]float64, float64) {
+	bb := Rectangle{Min: Point{X: math.Inf(1), Y: math.Inf(1)}, Max: Point{X: math.Inf(-1), Y: math.Inf(-1)}}
+	for _, p := range polys {
+		if p.NumVertices() > 0 {
+			bb = bb.union(p.BoundingBox())
+		}
+	}
+	areas := make([]float64, len(polys))
+	if math.IsInf(bb.Min.X, 1) {
+		return areas, 0
+	}
+	m := Translate(-bb.Min.X, -bb.Min.Y)
+	for i, p := range polys {
+		areas[i] = area(p.Transformed(m))
+	}
+	extent := math.Max(bb.Max.X-bb.Min.X, bb.Max.Y-bb.Min.Y)
+	return areas, 1e-9 * extent * extent
+}
+
+// lostArea returns a bound on the area the geometry listed in d may account
+// for: the squared lengths of the segments not divided, which may then be
+// taken as on the wrong side of the other operand, and the squared extents of
+// the chains not closed.
+func lostArea(d Diagnostics) float64 {
+	a := 0.0
+	for _, list := range [][]DroppedDivision{d.DroppedIntersections, d.DroppedDivisions} {
+		for _, dd := range list {
+			for _, s := range dd.Segments {
+				l := distance(s[0], s[1])
+				a += l * l
+			}
+		}
+	}
+	for _, c := range d.UnclosedChains {
+		if len(c) > 0 {
+			bb := c.BoundingBox()
+			extent := math.Max(bb.Max.X-bb.Min.X, bb.Max.Y-bb.Min.Y)
+			a += extent * extent
+		}
+	}
+	return a
+}
+
+// hasSliver returns whether p has a contour of an area within the tolerance of
+// fuzzAreas of zero.
+func hasSliver(p Polygon) bool {
+	for _, c := range p {
+		if areas, tol := fuzzAreas(Polygon{c}); areas[0] <= tol {
+			return true
+		}
+	}
+	return false
+}
+
+func numSegments(polys ...Polygon) int {
+	n := 0
+	for _, p := range polys {
+		n += p.NumVertices()
+	}
+	return n
+}
+
+func FuzzConstruct(f *testing.F) {
+	square := Polygon{{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}}}
+	f.Add(encodeFuzzPolygon(square), encodeFuzzPolygon(square.Transformed(Translate(1, 1))))
+	f.Add(encodeFuzzPolygon(square), encodeFuzzPolygon(square.Transformed(Rotate(math.Pi/4))))
+	f.Fuzz(func(t *testing.T, subjectData, clippingData []byte) {
+		// The operands must not intersect themselves.
+		subject, clipping := decodeFuzzPolygon(subjectData), decodeFuzzPolygon(clippingData)
+		snapFuzzPolygons(subject, clipping)
+		a, _ := fuzzRun(t, "Simplify(subject)", numSegments(subject), subject.Simplify)
+		b, _ := fuzzRun(t, "Simplify(clipping)", numSegments(clipping), clipping.Simplify)
+		if len(selfIntersections(a)) > 0 || len(selfIntersections(b)) > 0 {
+			t.Skip("Simplify left self-intersections, see FuzzSimplify")
+		}
+		// Nor may they have contours of next to no area, which cut the other
+		// operand into parts meeting along edges, with their vertices a few
+		// ulps apart.
+		if hasSliver(a) || hasSliver(b) {
+			t.Skip("operand with a contour of next to no area")
+		}
+
+		n := numSegments(a, b)
+		var results [4]Polygon
+		lost := 0.0
+		for op := UNION; op <= XOR; op++ {
+			var diag Diagnostics
+			results[op], diag = fuzzRun(t, op.String(), n, func(opts ...Option) Polygon {
+				return a.Construct(op, b, opts...)
+			})
+			lost += lostArea(diag)
+			if bad := selfIntersections(results[op]); len(bad) > 0 && diag.Empty() {
+				t.Errorf("%v: result intersects itself at %v\nsubject:  %v\nclipping: %v\nresult:   %v", op, bad[0], a, b, results[op])
+			}
+		}
+		lines, _ := fuzzRun(t, "CLIPLINE", n, func(opts ...Option) Polygon {
+			return a.Construct(CLIPLINE, b, opts...)
+		})
+		bMinusA, diag := fuzzRun(t, "DIFFERENCE(clipping, subject)", n, func(opts ...Option) Polygon {
+			return b.Construct(DIFFERENCE, a, opts...)
+		})
+		lost += lostArea(diag)
+		xor, diag := fuzzRun(t, "(A−B) ∪ (B−A)", numSegments(results[DIFFERENCE], bMinusA), func(opts ...Option) Polygon {
+			return results[DIFFERENCE].Construct(UNION, bMinusA, opts...)
+		})
+		lost += lostArea(diag)
+
+		// Clipped lines are parts of the subject's contours, but for where
+		// they follow the clipping's edges instead, which findIntersection
+		// takes as overlapping lines up to about 3e-8 of their length apart.
+		length := func(p Polygon) float64 {
+			l := 0.0
+			for _, c := range p {
+				for i := 1; i < len(c); i++ {
+					l += distance(c[i-1], c[i])
+				}
+			}
+			return l
+		}
+		if l, max := length(lines), length(a); l > max*(1+1e-7) {
+			t.Errorf("CLIPLINE: expected at most length %g, got %g", max, l)
+		}
+
+		// The areas must add up, up to what geometry was lost may account for.
+		areas, tol := fuzzAreas(a, b, results[UNION], results[INTERSECTION], results[DIFFERENCE], results[XOR], bMinusA, xor)
+		tol += lost
+		areaA, areaB, union, intersection, difference, symmetric, reverse, xorUnion := areas[0], areas[1], areas[2], areas[3], areas[4], areas[5], areas[6], areas[7]
+		for _, c := range []struct {
+			name        string
+			left, right float64
+		}{
+			{"|A∪B| + |A∩B| = |A| + |B|", union + intersection, areaA + areaB},
+			{"|A−B| = |A| − |A∩B|", difference, areaA - intersection},
+			{"|B−A| = |B| − |A∩B|", reverse, areaB - intersection},
+			{"|A⊕B| = |A∪B| − |A∩B|", symmetric, union - intersection},
+			{"A⊕B = (A−B) ∪ (B−A)", symmetric, xorUnion},
+		} {
+			if math.Abs(c.left-c.right) > tol {
+				t.Errorf("%s: %g ≠ %g, with %g lost\nsubject:  %v\nclipping: %v", c.name, c.left, c.right, lost, a, b)
+			}
+		}
+	})
+}
+
+func FuzzSimplify(f *testing.F) {
+	f.Add(encodeFuzzPolygon(Polygon{{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: 1}}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := decodeFuzzPolygon(data)
+		snapFuzzPolygons(p)
+		result, diag := fuzzRun(t, "Simplify", numSegments(p), p.Simplify)
+		if !diag.Empty() {
+			return
+		}
+		if bad := selfIntersections(result); len(bad) > 0 {
+			t.Errorf("result intersects itself at %v\npolygon: %v\nresult:  %v", bad[0], p, result)
+		}
+
+		// Simplifying again changes nothing of substance.
+		again, diag := fuzzRun(t, "Simplify again", numSegments(result), result.Simplify)
+		areas, tol := fuzzAreas(result, again)
+		if math.Abs(areas[0]-areas[1]) > tol+lostArea(diag) {
+			t.Errorf("expected area %g after simplifying again, got %g\npolygon: %v\nresult:  %v", areas[0], areas[1], p, result)
+		}
+	})
+}
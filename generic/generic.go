@@ -0,0 +1,194 @@
+// Package generic is a conversion wrapper around package polyclip for polygons
+// with coordinates of other numeric types, such as float32 for mesh data or
+// int32 for grid data:
+//
+//	a := generic.Polygon[int32]{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}
+//	b := generic.Polygon[int32]{{{5, 5}, {15, 5}, {15, 15}, {5, 15}}}
+//	c := a.Construct(polyclip.INTERSECTION, b) // [[{5 10} {5 5} {10 5} {10 10}]]
+//
+// The clipping engine is not generic. Each operation converts its operands to
+// polyclip.Polygon, runs the float64 engine, and converts the result back, at
+// the cost of a copy either way. The float64 engine represents all coordinates
+// of the supported types exactly, except for 64-bit integers beyond 2^53. Only
+// new vertices, such as intersection points, are rounded back to the coordinate
+// type. For integer types, rounding may make vertices coincide, which are then
+// merged, dropping contours that collapse.
+//
+// Polygon[float64] holds the same data as polyclip.Polygon, and converts to and
+// from it without loss.
+package generic
+
+import (
+	"math"
+
+	"github.com/ctessum/polyclip-go"
+)
+
+// Number is the set of supported coordinate types.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+// Point is a point with coordinates of type T; see polyclip.Point.
+type Point[T Number] struct {
+	X, Y T
+}
+
+// Equals returns true if both p1 and p2 describe exactly the same point.
+func (p1 Point[T]) Equals(p2 Point[T]) bool {
+	return p1.X == p2.X && p1.Y == p2.Y
+}
+
+// Rectangle is an axis-aligned rectangle; see polyclip.Rectangle.
+type Rectangle[T Number] struct {
+	Min, Max Point[T]
+}
+
+// Contour is a closed sequence of points; see polyclip.Contour.
+type Contour[T Number] []Point[T]
+
+// Polygon is carved out of a 2D plane by a set of contours; see polyclip.Polygon.
+type Polygon[T Number] []Contour[T]
+
+// NumVertices returns total number of all vertices of all contours of a polygon.
+func (p Polygon[T]) NumVertices() int {
+	num := 0
+	for _, c := range p {
+		num += len(c)
+	}
+	return num
+}
+
+// BoundingBox finds minimum and maximum coordinates of points in a polygon,
+// which must have at least one vertex.
+func (p Polygon[T]) BoundingBox() Rectangle[T] {
+	var bb Rectangle[T]
+	first := true
+	for _, c := range p {
+		for _, pt := range c {
+			if first {
+				bb.Min, bb.Max = pt, pt
+				first = false
+				continue
+			}
+			if pt.X < bb.Min.X {
+				bb.Min.X = pt.X
+			}
+			if pt.X > bb.Max.X {
+				bb.Max.X = pt.X
+			}
+			if pt.Y < bb.Min.Y {
+				bb.Min.Y = pt.Y
+			}
+			if pt.Y > bb.Max.Y {
+				bb.Max.Y = pt.Y
+			}
+		}
+	}
+	return bb
+}
+
+// Clone returns a duplicate of a polygon.
+func (p Polygon[T]) Clone() Polygon[T] {
+	r := make(Polygon[T], len(p))
+	for i, c := range p {
+		r[i] = append(Contour[T]{}, c...)
+	}
+	return r
+}
+
+// Construct computes a 2D polygon, which is a result of performing the
+// specified Boolean operation on the provided pair of polygons;
+// see polyclip.Polygon.Construct.
+func (p Polygon[T]) Construct(operation polyclip.Op, clipping Polygon[T], opts ...polyclip.Option) Polygon[T] {
+	// CLIPLINE returns polylines, which may end where they start.
+	return fromFloat64[T](p.Float64().Construct(operation, clipping.Float64(), opts...), operation != polyclip.CLIPLINE)
+}
+
+// Simplify removes self-intersections from p; see polyclip.Polygon.Simplify.
+func (p Polygon[T]) Simplify(opts ...polyclip.Option) Polygon[T] {
+	return FromFloat64[T](p.Float64().Simplify(opts...))
+}
+
+// Float64 returns p with float64 coordinates.
+func (p Polygon[T]) Float64() polyclip.Polygon {
+	r := make(polyclip.Polygon, len(p))
+	for i, c := range p {
+		r[i] = make(polyclip.Contour, len(c))
+		for j, pt := range c {
+			r[i][j] = polyclip.Point{X: float64(pt.X), Y: float64(pt.Y)}
+		}
+	}
+	return r
+}
+
+// FromFloat64 converts p to coordinates of type T, rounding them to the nearest
+// representable values and clamping those beyond the range of an integer type
+// to it. Consecutive points that become equal are merged, and
+// contours collapsing to fewer than three points are dropped.
+// The contours are taken to be closed, so that a last point equal to the
+// first is merged with it too.
+func FromFloat64[T Number](p polyclip.Polygon) Polygon[T] {
+	return fromFloat64[T](p, true)
+}
+
+// fromFloat64 is FromFloat64 for closed contours, or, if closed is false, for
+// open polylines, which keep their last point and are dropped only when they
+// collapse to a single point.
+func fromFloat64[T Number](p polyclip.Polygon, closed bool) Polygon[T] {
+	r := make(Polygon[T], 0, len(p))
+	for _, c := range p {
+		var con Contour[T]
+		for _, pt := range c {
+			q := Point[T]{convert[T](pt.X), convert[T](pt.Y)}
+			if len(con) == 0 || !con[len(con)-1].Equals(q) {
+				con = append(con, q)
+			}
+		}
+		if !closed {
+			if len(con) >= 2 || len(con) == len(c) {
+				r = append(r, con)
+			}
+			continue
+		}
+		for len(con) > 1 && con[0].Equals(con[len(con)-1]) {
+			con = con[:len(con)-1]
+		}
+		if len(con) >= 3 || len(con) == len(c) {
+			r = append(r, con)
+		}
+	}
+	return r
+}
+
+// convert rounds f to the nearest value of type T. For integer types, values
+// out of range are clamped to the smallest or largest value of T, where
+// conversion would otherwise wrap around silently.
+func convert[T Number](f float64) T {
+	half := 0.5
+	if T(half) != 0 {
+		return T(f)
+	}
+	// T is an integer type, for which conversion would truncate.
+	min, max := intRange[T]()
+	switch f = math.Round(f); {
+	case f < float64(min):
+		return min
+	case f >= -float64(min):
+		// float64(max) may round up to -min, which is out of range.
+		return max
+	}
+	return T(f)
+}
+
+// intRange returns the smallest and largest values of the signed integer type T.
+func intRange[T Number]() (min, max T) {
+	// Double m until that overflows, leaving the largest power of two below
+	// the largest value.
+	m := T(1)
+	for m*2 > m {
+		m *= 2
+	}
+	max = m - 1 + m
+	return -max - 1, max
+}
@@ -0,0 +1,95 @@
+package generic
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ctessum/polyclip-go"
+)
+
+func TestConstructInt32(t *testing.T) {
+	a := Polygon[int32]{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}
+	b := Polygon[int32]{{{5, 5}, {15, 5}, {15, 15}, {5, 15}}}
+	got := a.Construct(polyclip.INTERSECTION, b)
+	want := Polygon[int32]{{{5, 10}, {5, 5}, {10, 5}, {10, 10}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// The diagonals cross at (5, 4.5), which is rounded to (5, 5).
+	upper := Polygon[int32]{{{0, 0}, {10, 9}, {0, 9}}}
+	lower := Polygon[int32]{{{0, 0}, {10, 0}, {0, 9}}}
+	got = upper.Construct(polyclip.INTERSECTION, lower)
+	if len(got) != 1 || !reflect.DeepEqual(got.BoundingBox(), Rectangle[int32]{Max: Point[int32]{5, 9}}) {
+		t.Errorf("expected a single triangle from {0 0} to {5 5} and {0 9}, got %v", got)
+	}
+}
+
+func TestConstructClipLine(t *testing.T) {
+	// The line enters the square at {2.67 10}, which is rounded to {3 10},
+	// where it also ends. Unlike a contour, the line keeps both ends.
+	line := Polygon[int32]{{{2, 12}, {3, 9}, {3, 10}}}
+	square := Polygon[int32]{{{0, 0}, {10, 0}, {10, 10}, {0, 10}}}
+	got := line.Construct(polyclip.CLIPLINE, square)
+	if len(got) != 1 || len(got[0]) != 3 || !got[0][0].Equals(got[0][2]) || !got[0][0].Equals(Point[int32]{3, 10}) {
+		t.Errorf("expected a line from {3 10} to {3 9} and back, got %v", got)
+	}
+}
+
+func TestConstructFloat32(t *testing.T) {
+	a := Polygon[float32]{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}
+	b := Polygon[float32]{{{0.5, 0.5}, {1.5, 0.5}, {1.5, 1.5}, {0.5, 1.5}}}
+	got := a.Construct(polyclip.UNION, b)
+	if got.NumVertices() != 8 {
+		t.Errorf("expected 8 vertices, got %v", got)
+	}
+	want := Rectangle[float32]{Max: Point[float32]{1.5, 1.5}}
+	if bb := got.BoundingBox(); bb != want {
+		t.Errorf("expected bounding box %v, got %v", want, bb)
+	}
+}
+
+func TestSimplifyOptions(t *testing.T) {
+	// A bowtie, which Simplify splits into two triangles at (5, 5).
+	p := Polygon[int16]{{{0, 0}, {10, 10}, {10, 0}, {0, 10}}}
+	var s polyclip.Stats
+	got := p.Simplify(polyclip.WithStats(&s))
+	if len(got) != 2 || got.NumVertices() != 6 {
+		t.Errorf("expected two triangles, got %v", got)
+	}
+	if s.InputSegments != 4 {
+		t.Errorf("expected 4 input segments in the statistics, got %d", s.InputSegments)
+	}
+}
+
+func TestFloat64RoundTrip(t *testing.T) {
+	p := polyclip.Polygon{{{0.1, 0.2}, {3, 0.25}, {1, 7.5}}}
+	if got := FromFloat64[float64](p).Float64(); !reflect.DeepEqual(got, p) {
+		t.Errorf("expected %v, got %v", p, got)
+	}
+	// Rounding collapses the second contour.
+	q := polyclip.Polygon{{{0.4, 0}, {3.6, 0}, {2, 2.5}}, {{0.1, 0.1}, {0.2, 0.3}, {0.4, 0.1}}}
+	want := Polygon[int]{{{0, 0}, {4, 0}, {2, 3}}}
+	if got := FromFloat64[int](q); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestFromFloat64Clamps(t *testing.T) {
+	p := polyclip.Polygon{{{-1e6, -40000.4}, {1e6, 0}, {200, 1e30}}}
+	if got, want := FromFloat64[int8](p), (Polygon[int8]{{{-128, -128}, {127, 0}, {127, 127}}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("int8: expected %v, got %v", want, got)
+	}
+	if got, want := FromFloat64[int16](p), (Polygon[int16]{{{-32768, -32768}, {32767, 0}, {200, 32767}}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("int16: expected %v, got %v", want, got)
+	}
+	if got, want := FromFloat64[int32](p), (Polygon[int32]{{{-1e6, -40000}, {1e6, 0}, {200, math.MaxInt32}}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("int32: expected %v, got %v", want, got)
+	}
+	// The bounds of int64 are not exactly representable as float64 values.
+	q := polyclip.Polygon{{{math.MinInt64, 0}, {math.MaxInt64, 0}, {0, 1e19}, {0, -1e19}}}
+	if got, want := FromFloat64[int64](q), (Polygon[int64]{{{math.MinInt64, 0}, {math.MaxInt64, 0}, {0, math.MaxInt64}, {0, math.MinInt64}}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("int64: expected %v, got %v", want, got)
+	}
+}
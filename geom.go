@@ -26,6 +26,7 @@
 package polyclip
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/gonum/floats"
@@ -78,6 +79,19 @@ type segment struct {
 	start, end Point
 }
 
+// Segment is a straight line segment between two points.
+type Segment struct {
+	Start, End Point
+}
+
+func (s Segment) segment() segment {
+	return segment{s.Start, s.End}
+}
+
+func (s segment) Segment() Segment {
+	return Segment{s.start, s.end}
+}
+
 // Contour represents a sequence of vertices connected by line segments, forming a closed shape.
 type Contour []Point
 
@@ -227,6 +241,15 @@ const (
 	CLIPLINE // CLIPLINE assumes that the subject polygon is actually a line string and clips it.
 )
 
+var opNames = [...]string{"UNION", "INTERSECTION", "DIFFERENCE", "XOR", "CLIPLINE"}
+
+func (op Op) String() string {
+	if op < 0 || int(op) >= len(opNames) {
+		return fmt.Sprintf("Op(%d)", int(op))
+	}
+	return opNames[op]
+}
+
 // Construct computes a 2D polygon, which is a result of performing
 // specified Boolean operation on the provided pair of polygons (p <Op> clipping).
 // It uses algorithm described by F. Martínez, A. J. Rueda, F. R. Feito
@@ -237,10 +260,14 @@ const (
 // k is number of intersections of all polygon edges.
 // This function is not designed to handle self-intersecting polygons;
 // Remove self-intersections first using the Simplify function.
-func (p Polygon) Construct(operation Op, clipping Polygon) Polygon {
+// Additional results can be requested by passing options such as WithProvenance.
+func (p Polygon) Construct(operation Op, clipping Polygon, opts ...Option) Polygon {
 	c := clipper{
 		subject:  p,
 		clipping: clipping,
 	}
+	for _, opt := range opts {
+		opt(&c)
+	}
 	return c.compute(operation)
 }
@@ -1,6 +1,6 @@
 module github.com/radean0909/polyclip-go
 
-go 1.12
+go 1.18
 
 require (
 	github.com/gonum/floats v0.0.0-20181209220543-c233463c7e82
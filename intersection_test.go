@@ -1,6 +1,11 @@
 package polyclip
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
 
 func TestFindIntersection(t *testing.T) {
 	cases := []struct {
@@ -74,7 +79,37 @@ func TestFindIntersection(t *testing.T) {
 		{ // Overlapping segments
 			segment{Point{41.57979856674331, 170.60307379214092}, Point{43.2635182233307, 170.15192246987792}},
 			segment{Point{43.2635182233307, 170.15192246987792}, Point{42.78116786015871, 170.28116786015872}},
-			2, Point{43.2635182233307, 170.15192246987792},
+			2, Point{42.78116786015871, 170.28116786015872},
+		},
+		{ // Overlapping segments of different lengths
+			segment{Point{9, 4}, Point{2, 4}},
+			segment{Point{0, 4}, Point{6, 4}},
+			2, Point{6, 4},
+		},
+		{ // Collinear segments apart
+			segment{Point{0, 0}, Point{2, 1}},
+			segment{Point{4, 2}, Point{6, 3}},
+			0, Point{},
+		},
+		{ // Short segment nearly aligned with a long one, not touching it
+			segment{Point{-5.4412744703586213, -0.11067402409389615}, Point{-5.4412744667667345, -0.11067402427351347}},
+			segment{Point{-5.4412744715227745, -0.11067402409389615}, Point{25.37997735227691, -1.6520331394858658}},
+			0, Point{},
+		},
+		{ // Nearly parallel lines crossing near the origin
+			segment{Point{-1, -1e-7}, Point{1, 1e-7}},
+			segment{Point{-1, 0}, Point{1, 0}},
+			1, Point{0, 0},
+		},
+		{ // Nearly parallel lines near the origin, not crossing
+			segment{Point{0, 1e-7}, Point{1, 2e-7}},
+			segment{Point{0, 0}, Point{1, 0}},
+			0, Point{},
+		},
+		{ // Parallel lines near the origin
+			segment{Point{0, 1e-9}, Point{1, 1e-9}},
+			segment{Point{0, 0}, Point{1, 0}},
+			0, Point{},
 		},
 		{ // Identical segments
 			segment{Point{66, 160}, Point{67.1242262770966, 147.15003485264717}},
@@ -88,3 +123,134 @@ func TestFindIntersection(t *testing.T) {
 		verify(t, ip1.Equals(v.ip1), "Case %d: Expected ip1 to be %v, but got %v", i, v.ip1, ip1)
 	}
 }
+
+// TestFindIntersectionSmallIntegers checks findIntersection against exact
+// orientation tests on all pairs of segments between points of a small grid
+// around the origin: crossing, touching, parallel and collinear ones. The
+// products of grid coordinates are exact.
+func TestFindIntersectionSmallIntegers(t *testing.T) {
+	orient := func(a, b, c Point) float64 {
+		return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+	}
+	var pts []Point
+	for x := -2.0; x <= 2; x++ {
+		for y := -2.0; y <= 2; y++ {
+			pts = append(pts, Point{x * 0.25, y * 0.25})
+		}
+	}
+	var segs []segment
+	for i, a := range pts {
+		for _, b := range pts[i+1:] {
+			segs = append(segs, segment{a, b})
+		}
+	}
+	for _, s0 := range segs {
+		for _, s1 := range segs {
+			o1, o2 := orient(s0.start, s0.end, s1.start), orient(s0.start, s0.end, s1.end)
+			o3, o4 := orient(s1.start, s1.end, s0.start), orient(s1.start, s1.end, s0.end)
+			if o1 == 0 && o2 == 0 {
+				// The segments overlap between the rightmost of their left
+				// ends and the leftmost of their right ends, if any.
+				l0, r0 := s0.start, s0.end
+				if pointLess(r0, l0) {
+					l0, r0 = r0, l0
+				}
+				l1, r1 := s1.start, s1.end
+				if pointLess(r1, l1) {
+					l1, r1 = r1, l1
+				}
+				lo, hi := l0, r0
+				if pointLess(lo, l1) {
+					lo = l1
+				}
+				if pointLess(r1, hi) {
+					hi = r1
+				}
+				want := 2
+				switch {
+				case pointLess(hi, lo):
+					want = 0
+				case lo.Equals(hi):
+					want = 1
+				}
+				num, ip0, ip1 := findIntersection(s0, s1, true)
+				switch {
+				case num != want:
+					t.Errorf("%v and %v: expected %d intersections, got %d", s0, s1, want, num)
+				case num == 1 && !ip0.Equals(lo):
+					t.Errorf("%v and %v: expected intersection at %v, got %v", s0, s1, lo, ip0)
+				case num == 2 && !(ip0.Equals(lo) && ip1.Equals(hi) || ip0.Equals(hi) && ip1.Equals(lo)):
+					t.Errorf("%v and %v: expected overlap from %v to %v, got %v to %v", s0, s1, lo, hi, ip0, ip1)
+				}
+				continue
+			}
+			want := 0
+			if o1*o2 <= 0 && o3*o4 <= 0 {
+				want = 1
+			}
+			if num, _, _ := findIntersection(s0, s1, true); num != want {
+				t.Errorf("%v and %v: expected %d intersections, got %d", s0, s1, want, num)
+			}
+		}
+	}
+}
+
+// residual returns the distance of p from the line through s, computed exactly
+// up to the final square root.
+func residual(p Point, s segment) float64 {
+	a, b, q := newRatPoint(s.start), newRatPoint(s.end), newRatPoint(p)
+	cross := ratCross(a, b, q)
+	dx, dy := ratSub(b.x, a.x), ratSub(b.y, a.y)
+	sqr := new(big.Rat).Quo(ratMul(cross, cross), new(big.Rat).Add(ratMul(dx, dx), ratMul(dy, dy)))
+	f, _ := sqr.Float64()
+	return math.Sqrt(f)
+}
+
+func TestFindIntersectionResidual(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	for _, c := range []struct {
+		name   string
+		scale  float64 // magnitude of the coordinates
+		length float64 // of the segments
+		angle  float64 // between the segments
+	}{
+		{"short", 1, 1, 1},
+		{"long", 1e6, 1e6, 0.5},
+		{"nearly parallel", 100, 100, 1e-9},
+		{"long and nearly parallel", 1e6, 1e6, 1e-10},
+		{"far from origin", 1e9, 10, 1e-6},
+	} {
+		var max, naiveMax float64
+		ulp := math.Nextafter(c.scale, math.Inf(1)) - c.scale
+		for i := 0; i < 1000; i++ {
+			center := Point{c.scale * (1 + rnd.Float64()), c.scale * (1 + rnd.Float64())}
+			dir := rnd.Float64() * 2 * math.Pi
+			seg := func(angle, offset float64) segment {
+				dx, dy := math.Cos(angle)*c.length/2, math.Sin(angle)*c.length/2
+				// Move the crossing point away from the middle of the segments.
+				cx, cy := center.X+dx*offset, center.Y+dy*offset
+				return segment{Point{cx - dx, cy - dy}, Point{cx + dx, cy + dy}}
+			}
+			seg0 := seg(dir, rnd.Float64()-0.5)
+			seg1 := seg(dir+c.angle, rnd.Float64()-0.5)
+			num, p, _ := findIntersection(seg0, seg1, true)
+			if num != 1 {
+				continue
+			}
+			max = math.Max(max, math.Max(residual(p, seg0), residual(p, seg1)))
+
+			// The plain float64 construction, for comparison.
+			d0 := Point{seg0.end.X - seg0.start.X, seg0.end.Y - seg0.start.Y}
+			d1 := Point{seg1.end.X - seg1.start.X, seg1.end.Y - seg1.start.Y}
+			e := Point{seg1.start.X - seg0.start.X, seg1.start.Y - seg0.start.Y}
+			s := (e.X*d1.Y - e.Y*d1.X) / (d0.X*d1.Y - d0.Y*d1.X)
+			naive := Point{seg0.start.X + s*d0.X, seg0.start.Y + s*d0.Y}
+			naiveMax = math.Max(naiveMax, math.Max(residual(naive, seg0), residual(naive, seg1)))
+		}
+		t.Logf("%s: maximum residual %g ulp, plain float64 %g ulp", c.name, max/ulp, naiveMax/ulp)
+		// Rounding the exact intersection point to float64 coordinates alone
+		// moves it by up to half an ulp of each coordinate; the coordinates
+		// range up to twice the scale.
+		verify(t, max <= 2*ulp, "%s: expected residual of at most 2 ulp, got %g ulp", c.name, max/ulp)
+	}
+}
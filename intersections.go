@@ -0,0 +1,206 @@
+package polyclip
+
+import "sort"
+
+// IntersectionKind describes how two segments meet.
+type IntersectionKind int
+
+const (
+	// CROSSING is a single point lying in the interior of both segments.
+	CROSSING IntersectionKind = iota
+	// TOUCHING is a single point that is an endpoint of at least one of the segments.
+	TOUCHING
+	// OVERLAP is a collinear stretch shared by both segments.
+	OVERLAP
+)
+
+// IntersectionResult describes where the segments with indices I and J (I < J) meet.
+// For CROSSING and TOUCHING results Point holds the intersection point;
+// for OVERLAP results Overlap holds the shared part, ordered from left to right,
+// and Point its left end.
+type IntersectionResult struct {
+	Kind    IntersectionKind
+	I, J    int
+	Point   Point
+	Overlap Segment
+}
+
+// Intersections reports every pair of segments in segs that cross, touch or overlap.
+// It uses the same sweep as Construct (a variant of the Bentley–Ottmann algorithm),
+// so it runs in time O((n+k) log n) for n segments with k intersections.
+// Zero-length segments are ignored.
+// Results are sorted by I, then J, then position.
+func Intersections(segs []Segment) []IntersectionResult {
+	c := new(clipper)
+	// Move the segments as Construct moves its operands, taking their ends as
+	// a single contour.
+	var ends Contour
+	for _, s := range segs {
+		ends = append(ends, s.Start, s.End)
+	}
+	ends = c.condition(Polygon{ends})[0][0]
+	moved := make([]segment, len(segs))
+	for i := range segs {
+		moved[i] = segment{ends[2*i], ends[2*i+1]}
+		addProcessedSegment(&c.eventQueue, moved[i], _SUBJECT, 0, i)
+	}
+	original := func(e *endpoint) segment { return moved[e.edge] }
+	pieces := c.subdivide(c.splitAtIntersections(original), nil)
+
+	type pair struct{ i, j int }
+	ordered := func(i, j int) pair {
+		if i > j {
+			i, j = j, i
+		}
+		return pair{i, j}
+	}
+
+	// After the sweep, any two pieces either share nothing but their endpoints,
+	// or are identical. Identical pieces of different edges make up overlaps,
+	// shared endpoints make up single-point intersections.
+	overlaps := make(map[pair]segment)
+	atPoint := make(map[Point][]int)
+	byPiece := make(map[segment][]int)
+	addEdge := func(edges []int, edge int) []int {
+		for _, e := range edges {
+			if e == edge {
+				return edges
+			}
+		}
+		return append(edges, edge)
+	}
+	for _, e := range pieces {
+		s := segment{e.other.p, e.p} // e is the right endpoint of the piece
+		byPiece[s] = addEdge(byPiece[s], e.edge)
+		atPoint[s.start] = addEdge(atPoint[s.start], e.edge)
+		atPoint[s.end] = addEdge(atPoint[s.end], e.edge)
+	}
+	for s, edges := range byPiece {
+		for a := range edges {
+			for b := a + 1; b < len(edges); b++ {
+				k := ordered(edges[a], edges[b])
+				o, ok := overlaps[k]
+				if !ok {
+					overlaps[k] = s
+					continue
+				}
+				if pointLess(s.start, o.start) {
+					o.start = s.start
+				}
+				if pointLess(o.end, s.end) {
+					o.end = s.end
+				}
+				overlaps[k] = o
+			}
+		}
+	}
+
+	var result []IntersectionResult
+	for k, o := range overlaps {
+		o = segment{c.revertPoint(o.start), c.revertPoint(o.end)}
+		result = append(result, IntersectionResult{Kind: OVERLAP, I: k.i, J: k.j, Point: o.start, Overlap: o.Segment()})
+	}
+	for p, edges := range atPoint {
+		for a := range edges {
+			for b := a + 1; b < len(edges); b++ {
+				k := ordered(edges[a], edges[b])
+				if o, ok := overlaps[k]; ok && !pointLess(p, o.start) && !pointLess(o.end, p) {
+					continue // already reported as part of an overlap
+				}
+				kind := CROSSING
+				if isEndpointOf(p, moved[k.i].Segment()) || isEndpointOf(p, moved[k.j].Segment()) {
+					kind = TOUCHING
+				}
+				result = append(result, IntersectionResult{Kind: kind, I: k.i, J: k.j, Point: c.revertPoint(p)})
+			}
+		}
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		ra, rb := result[a], result[b]
+		if ra.I != rb.I {
+			return ra.I < rb.I
+		}
+		if ra.J != rb.J {
+			return ra.J < rb.J
+		}
+		return pointLess(ra.Point, rb.Point)
+	})
+	return result
+}
+
+// pointLess orders points from left to right, and from bottom to top.
+// For collinear points this is also their order along the line.
+func pointLess(p1, p2 Point) bool {
+	if p1.X != p2.X {
+		return p1.X < p2.X
+	}
+	return p1.Y < p2.Y
+}
+
+func isEndpointOf(p Point, s Segment) bool {
+	return p.Equals(s.Start) || p.Equals(s.End)
+}
+
+// splitAtIntersections returns a function that divides e1 and e2 at every point
+// where they meet, so that afterwards they either share only endpoints or are
+// identical. Unlike possibleIntersection, it treats all segments alike
+// regardless of the polygon they belong to.
+//
+// Where the segments meet is computed from the input segments returned by
+// original, rather than from the pieces of them left by earlier divisions:
+// those start and end at rounded points, so that a point on an input segment
+// may lie just off the line of the piece containing it.
+func (c *clipper) splitAtIntersections(original func(e *endpoint) segment) func(e1, e2 *endpoint) []*endpoint {
+	return func(e1, e2 *endpoint) []*endpoint {
+		s1, s2 := original(e1), original(e2)
+		numIntersections, ip1, _ := findIntersection(s1, s2, true)
+		if numIntersections == 0 {
+			return nil
+		}
+
+		var points []Point
+		if numIntersections == 1 {
+			// Adjust for floating point imprecision when intersections are created at endpoints, which
+			// otherwise has the tendency to corrupt the original polygons with new, almost-parallel segments.
+			points = append(points, snap(ip1, e1.p, e2.p, e1.other.p, e2.other.p, s1.start, s1.end, s2.start, s2.end))
+		} else {
+			// The segments are collinear, so the ends of the overlap are those
+			// endpoints of either segment that lie within the other one.
+			for _, p := range []Point{e1.p, e1.other.p, e2.p, e2.other.p} {
+				if within(p, e1) && within(p, e2) {
+					points = append(points, p)
+				}
+			}
+			// Divide at the rightmost point first, so that the left part
+			// stays associated with e for the second division.
+			sort.Slice(points, func(i, j int) bool { return pointLess(points[j], points[i]) })
+		}
+
+		var divided []*endpoint
+		for _, e := range []*endpoint{e1, e2} {
+			wasDivided := false
+			for _, p := range points {
+				// The input segments may also meet beyond the pieces, where
+				// other pieces of them do.
+				if p.Equals(e.p) || p.Equals(e.other.p) || !within(p, e) {
+					continue
+				}
+				if c.divideSegment(e, p) != nil {
+					wasDivided = true
+				}
+			}
+			if wasDivided {
+				divided = append(divided, e)
+			}
+		}
+		return divided
+	}
+}
+
+// within returns whether p, known to be collinear with the segment of e,
+// lies between its endpoints.
+func within(p Point, e *endpoint) bool {
+	l, r := e.leftRight()
+	return !pointLess(p, l) && !pointLess(r, p)
+}
@@ -0,0 +1,163 @@
+package polyclip
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestIntersections(t *testing.T) {
+	cases := []struct {
+		name string
+		segs []Segment
+		want []IntersectionResult
+	}{
+		{
+			name: "cross",
+			segs: []Segment{{Point{0, 0}, Point{2, 2}}, {Point{0, 2}, Point{2, 0}}},
+			want: []IntersectionResult{{Kind: CROSSING, I: 0, J: 1, Point: Point{1, 1}}},
+		},
+		{
+			name: "disjoint",
+			segs: []Segment{{Point{0, 0}, Point{1, 0}}, {Point{0, 1}, Point{1, 1}}},
+		},
+		{
+			name: "touch",
+			segs: []Segment{{Point{0, 0}, Point{2, 0}}, {Point{1, 0}, Point{1, 3}}},
+			want: []IntersectionResult{{Kind: TOUCHING, I: 0, J: 1, Point: Point{1, 0}}},
+		},
+		{
+			name: "shared endpoint",
+			segs: []Segment{{Point{0, 0}, Point{1, 1}}, {Point{1, 1}, Point{2, 0}}},
+			want: []IntersectionResult{{Kind: TOUCHING, I: 0, J: 1, Point: Point{1, 1}}},
+		},
+		{
+			name: "overlap",
+			segs: []Segment{{Point{0, 1}, Point{3, 1}}, {Point{2, 1}, Point{1, 1}}},
+			want: []IntersectionResult{{Kind: OVERLAP, I: 0, J: 1, Point: Point{1, 1}, Overlap: Segment{Point{1, 1}, Point{2, 1}}}},
+		},
+		{
+			name: "partial overlap and crossing",
+			segs: []Segment{
+				{Point{0, 0}, Point{4, 0}},
+				{Point{2, 0}, Point{6, 0}},
+				{Point{3, -1}, Point{3, 1}},
+			},
+			want: []IntersectionResult{
+				{Kind: OVERLAP, I: 0, J: 1, Point: Point{2, 0}, Overlap: Segment{Point{2, 0}, Point{4, 0}}},
+				{Kind: CROSSING, I: 0, J: 2, Point: Point{3, 0}},
+				{Kind: CROSSING, I: 1, J: 2, Point: Point{3, 0}},
+			},
+		},
+		{
+			name: "three through one point",
+			segs: []Segment{
+				{Point{0, 0}, Point{2, 2}},
+				{Point{0, 2}, Point{2, 0}},
+				{Point{1, 0}, Point{1, 2}},
+			},
+			want: []IntersectionResult{
+				{Kind: CROSSING, I: 0, J: 1, Point: Point{1, 1}},
+				{Kind: CROSSING, I: 0, J: 2, Point: Point{1, 1}},
+				{Kind: CROSSING, I: 1, J: 2, Point: Point{1, 1}},
+			},
+		},
+		{
+			// Short segments on lines 4e-9 apart are as far apart for their
+			// length as ones of length 1 on lines 0.2 apart.
+			name: "short parallel",
+			segs: []Segment{{Point{1, 1}, Point{1 + 2e-8, 1}}, {Point{1 + 1e-8, 1 + 4e-9}, Point{1 + 3e-8, 1 + 4e-9}}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Intersections(c.segs)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("expected:\n%v\ngot:\n%v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestIntersectionsSelfIntersectingContour(t *testing.T) {
+	bowtie := Contour{{0, 0}, {2, 2}, {2, 0}, {0, 2}}
+	var segs []Segment
+	for i := range bowtie {
+		segs = append(segs, bowtie.segment(i).Segment())
+	}
+	var crossings []IntersectionResult
+	for _, r := range Intersections(segs) {
+		if r.Kind != TOUCHING {
+			crossings = append(crossings, r)
+		}
+	}
+	want := []IntersectionResult{{Kind: CROSSING, I: 0, J: 2, Point: Point{1, 1}}}
+	if !reflect.DeepEqual(crossings, want) {
+		t.Errorf("expected:\n%v\ngot:\n%v", want, crossings)
+	}
+}
+
+// TestIntersectionsBruteForce compares Intersections with exact tests on every
+// pair of random segments between points of a small integer grid, where many
+// segments touch, cross at vertices of others or overlap.
+func TestIntersectionsBruteForce(t *testing.T) {
+	// The products of grid coordinates are exact.
+	orient := func(a, b, c Point) float64 {
+		return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+	}
+	inBox := func(p Point, s Segment) bool {
+		return p.X >= math.Min(s.Start.X, s.End.X) && p.X <= math.Max(s.Start.X, s.End.X) &&
+			p.Y >= math.Min(s.Start.Y, s.End.Y) && p.Y <= math.Max(s.Start.Y, s.End.Y)
+	}
+	// meet returns whether a and b meet, and whether they share more than a point.
+	meet := func(a, b Segment) (bool, bool) {
+		o1, o2 := orient(a.Start, a.End, b.Start), orient(a.Start, a.End, b.End)
+		if o1 == 0 && o2 == 0 {
+			var shared []Point
+			for _, p := range []Point{a.Start, a.End} {
+				if inBox(p, b) {
+					shared = append(shared, p)
+				}
+			}
+			for _, p := range []Point{b.Start, b.End} {
+				if inBox(p, a) {
+					shared = append(shared, p)
+				}
+			}
+			overlap := false
+			for _, p := range shared {
+				overlap = overlap || !p.Equals(shared[0])
+			}
+			return len(shared) > 0, overlap
+		}
+		o3, o4 := orient(b.Start, b.End, a.Start), orient(b.Start, b.End, a.End)
+		return o1*o2 <= 0 && o3*o4 <= 0, false
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	point := func() Point { return Point{float64(rnd.Intn(10)), float64(rnd.Intn(10))} }
+	for n := 0; n < 500; n++ {
+		var segs []Segment
+		for len(segs) < 3+n%15 {
+			if s := (Segment{point(), point()}); !s.Start.Equals(s.End) {
+				segs = append(segs, s)
+			}
+		}
+		got := make(map[[2]int]IntersectionKind)
+		for _, r := range Intersections(segs) {
+			got[[2]int{r.I, r.J}] = r.Kind
+		}
+		for i := range segs {
+			for j := i + 1; j < len(segs); j++ {
+				kind, found := got[[2]int{i, j}]
+				switch meets, overlap := meet(segs[i], segs[j]); {
+				case meets != found:
+					t.Errorf("%v: segments %d and %d: expected meeting %t, got %t", segs, i, j, meets, found)
+				case found && overlap != (kind == OVERLAP):
+					t.Errorf("%v: segments %d and %d: expected overlap %t, got kind %d", segs, i, j, overlap, kind)
+				}
+			}
+		}
+	}
+}
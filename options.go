@@ -0,0 +1,76 @@
+package polyclip
+
+// Option configures optional behaviour of, and requests additional results from,
+// the Boolean operations.
+type Option func(*clipper)
+
+// Source identifies the operand of a Boolean operation an edge originates from.
+type Source int
+
+const (
+	SUBJECT  Source = iota // the polygon Construct is called on
+	CLIPPING               // the polygon passed to Construct
+)
+
+// EdgeOrigin locates the input edge a result edge was derived from: edge Edge
+// (between vertices Edge and Edge+1) of contour Contour of the Source polygon.
+type EdgeOrigin struct {
+	Source        Source
+	Contour, Edge int
+}
+
+// Provenance holds the origin of every edge of a result polygon.
+// Provenance[i][j] describes the edge between vertices j and j+1 of contour i;
+// for closed contours the last entry describes the closing edge.
+// Where the operands share an edge, the origin is one of the two input edges.
+type Provenance [][]EdgeOrigin
+
+// WithProvenance makes Construct or Simplify fill prov with the origin of each
+// edge of its result.
+func WithProvenance(prov *Provenance) Option {
+	return func(c *clipper) {
+		c.provenance = prov
+	}
+}
+
+// Conditioning selects how Construct moves its operands before the sweep.
+// The sweep compares coordinates using tolerances that are partly absolute,
+// which suits coordinates of the order of the polygons' size, but not those far
+// from the origin, such as UTM coordinates in the millions. Along each axis
+// where the operands lie far from the origin relative to their extent, they are
+// translated to be centred on the origin. Operands of an extent below about
+// 1e-9 are also scaled by a power of two to an extent of about 1, unless the
+// mode is CONDITION_NONE. Both are exact, so input vertices come back
+// unchanged; new vertices are rounded once more.
+//
+// The other operations built on the same sweep, such as Overlay, Relate and
+// Intersections, move their operands like Construct does by default.
+type Conditioning int
+
+const (
+	CONDITION_TRANSLATE Conditioning = iota // translate along axes far from the origin (the default)
+	CONDITION_NONE                          // leave the operands as they are
+	CONDITION_SCALE                         // also scale by a power of two to an extent of about 1
+)
+
+// WithConditioning sets how Construct or Simplify moves its operands before the sweep.
+func WithConditioning(mode Conditioning) Option {
+	return func(c *clipper) {
+		c.conditioning = mode
+	}
+}
+
+// WithLinkTolerance makes Construct or Simplify treat endpoints of result edges
+// within epsilon of each other as equal when linking the edges into contours,
+// so that intersection points computed twice with slightly different results
+// do not leave contours unclosed. Contours that are still left open are closed
+// if their ends lie within epsilon of each other, joining several open
+// contours if needed.
+//
+// Without this option, endpoints must be equal to link up, and contours left
+// open are dropped.
+func WithLinkTolerance(epsilon float64) Option {
+	return func(c *clipper) {
+		c.linkTolerance = epsilon
+	}
+}
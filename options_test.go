@@ -0,0 +1,204 @@
+package polyclip
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWithProvenance(t *testing.T) {
+	subject := Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}}
+	clipping := Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}}, {{5, 5}, {6, 5}, {6, 6}}}
+	operands := []Polygon{subject, clipping}
+
+	for _, op := range []Op{UNION, INTERSECTION, DIFFERENCE, XOR, CLIPLINE} {
+		var prov Provenance
+		result := subject.Construct(op, clipping, WithProvenance(&prov))
+		verify(t, len(prov) == len(result), "%v: expected %d contours in provenance, got %d", op, len(result), len(prov))
+		sources := map[Source]bool{}
+		for i, cont := range result {
+			for j, o := range prov[i] {
+				verify(t, o.Contour >= 0, "%v: no origin for edge %d of contour %d", op, j, i)
+				if o.Contour < 0 {
+					continue
+				}
+				sources[o.Source] = true
+				in := operands[o.Source][o.Contour].segment(o.Edge)
+				out := cont.segment(j)
+				verify(t, signedArea(in.start, in.end, out.start) == 0 && signedArea(in.start, in.end, out.end) == 0,
+					"%v: edge %v is not on its origin %v", op, out, in)
+			}
+		}
+		if op != CLIPLINE {
+			verify(t, sources[SUBJECT] && sources[CLIPPING], "%v: expected edges from both operands, got %v", op, sources)
+		}
+	}
+}
+
+func TestWithProvenanceSimplify(t *testing.T) {
+	// A bowtie, split into two triangles where its edges cross.
+	p := Polygon{{{0, 0}, {2, 2}, {2, 0}, {0, 2}}}
+	var prov Provenance
+	result := p.Simplify(WithProvenance(&prov))
+	verify(t, len(result) == 2 && len(prov) == len(result), "expected provenance of two contours, got %v for %v", prov, result)
+	for i, cont := range result {
+		verify(t, len(prov[i]) == len(cont), "expected %d origins for contour %d, got %d", len(cont), i, len(prov[i]))
+		for j, o := range prov[i] {
+			verify(t, o.Source == SUBJECT && o.Contour == 0 && o.Edge >= 0, "no origin for edge %d of contour %d: %v", j, i, o)
+			if o.Edge < 0 {
+				continue
+			}
+			in, out := p[0].segment(o.Edge), cont.segment(j)
+			verify(t, signedArea(in.start, in.end, out.start) == 0 && signedArea(in.start, in.end, out.end) == 0,
+				"edge %v is not on its origin %v", out, in)
+		}
+	}
+}
+
+func TestWithProvenanceTrivial(t *testing.T) {
+	subject := Polygon{{{0, 0}, {1, 0}, {1, 1}}}
+	clipping := Polygon{{{5, 5}, {6, 5}, {6, 6}, {5, 6}}}
+	var prov Provenance
+	subject.Construct(UNION, clipping, WithProvenance(&prov))
+	want := Provenance{
+		{{SUBJECT, 0, 0}, {SUBJECT, 0, 1}, {SUBJECT, 0, 2}},
+		{{CLIPPING, 0, 0}, {CLIPPING, 0, 1}, {CLIPPING, 0, 2}, {CLIPPING, 0, 3}},
+	}
+	verify(t, len(prov) == len(want), "expected %v, got %v", want, prov)
+	for i := range want {
+		for j := range want[i] {
+			verify(t, prov[i][j] == want[i][j], "expected %v, got %v", want, prov)
+		}
+	}
+}
+
+func TestWithConditioning(t *testing.T) {
+	// The clipping edge crosses the bottom edge of the subject 2^-27 away from
+	// its corner, well within the relative tolerance of snap at 5e6.
+	const off = 5e6
+	near := 1 - math.Ldexp(1, -27)
+	subject := Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}
+	clipping := Polygon{{{near, -1}, {2, -1}, {2, 2}, {near, 2}}}
+	move := Translate(off, off)
+
+	for _, mode := range []Conditioning{CONDITION_TRANSLATE, CONDITION_SCALE} {
+		for _, op := range []Op{UNION, INTERSECTION, DIFFERENCE, XOR} {
+			want := subject.Construct(op, clipping).Transformed(move)
+			got := subject.Transformed(move).Construct(op, clipping.Transformed(move), WithConditioning(mode))
+			verify(t, reflect.DeepEqual(got, want), "%v, mode %d: expected %v, got %v", op, mode, want, got)
+		}
+	}
+}
+
+// TestConditioningSweeps checks that the other operations sharing the sweep
+// move their operands like Construct, on the operands of TestWithConditioning.
+func TestConditioningSweeps(t *testing.T) {
+	const off = 5e6
+	near := 1 - math.Ldexp(1, -27)
+	a := Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}
+	b := Polygon{{{near, -1}, {2, -1}, {2, 2}, {near, 2}}}
+	move := Translate(off, off)
+	movedA, movedB := a.Transformed(move), b.Transformed(move)
+
+	want, got := Overlay(a, b), Overlay(movedA, movedB)
+	for _, x := range [][2]Polygon{{want.AOnly, got.AOnly}, {want.BOnly, got.BOnly}, {want.Both, got.Both}} {
+		verify(t, reflect.DeepEqual(x[1], x[0].Transformed(move)), "Overlay: expected %v, got %v", x[0].Transformed(move), x[1])
+	}
+
+	faces, movedFaces := OverlayN([]Polygon{a, b}), OverlayN([]Polygon{movedA, movedB})
+	verify(t, len(faces) == len(movedFaces), "OverlayN: expected %d faces, got %d", len(faces), len(movedFaces))
+	for i := range faces {
+		if i < len(movedFaces) {
+			want := faces[i].Polygon.Transformed(move)
+			verify(t, reflect.DeepEqual(movedFaces[i].Polygon, want), "OverlayN: expected %v, got %v", want, movedFaces[i].Polygon)
+		}
+	}
+
+	twice := func(n int) bool { return n == 2 }
+	cc := CoverageCount([]Polygon{a, b}, twice).Transformed(move)
+	movedCC := CoverageCount([]Polygon{movedA, movedB}, twice)
+	verify(t, reflect.DeepEqual(movedCC, cc), "CoverageCount: expected %v, got %v", cc, movedCC)
+
+	all := a.IntersectAll(b).Polygon.Transformed(move)
+	movedAll := movedA.IntersectAll(movedB).Polygon
+	verify(t, reflect.DeepEqual(movedAll, all), "IntersectAll: expected %v, got %v", all, movedAll)
+
+	both := Polygon{a[0], b[0]}
+	simple := both.Simplify().Transformed(move)
+	movedSimple := both.Transformed(move).Simplify()
+	verify(t, reflect.DeepEqual(movedSimple, simple), "Simplify: expected %v, got %v", simple, movedSimple)
+
+	m, movedM := Relate(a, b), Relate(movedA, movedB)
+	verify(t, m == movedM, "Relate: expected %v, got %v", m, movedM)
+
+	segs := []Segment{a[0].segment(0).Segment(), b[0].segment(3).Segment()}
+	movedSegs := []Segment{movedA[0].segment(0).Segment(), movedB[0].segment(3).Segment()}
+	is, movedIs := Intersections(segs), Intersections(movedSegs)
+	verify(t, len(is) == 1 && len(movedIs) == 1 && movedIs[0].Point == is[0].Point.Transformed(move),
+		"Intersections: expected %v moved, got %v", is, movedIs)
+}
+
+func TestWithConditioningExactVertices(t *testing.T) {
+	subject := Polygon{{{5123456.789, 3987654.321}, {5123460.1, 3987654.3}, {5123458.7, 3987659.9}}}
+	clipping := Polygon{{{5123457.3, 3987653.1}, {5123461.9, 3987656.2}, {5123456.1, 3987658.4}}}
+	input := map[Point]bool{}
+	for _, p := range []Polygon{subject, clipping} {
+		for _, pt := range p[0] {
+			input[pt] = true
+		}
+	}
+	result := subject.Construct(UNION, clipping)
+	found := 0
+	for _, pt := range result[0] {
+		if input[pt] {
+			found++
+		}
+	}
+	// No vertex of either triangle lies inside the other.
+	verify(t, found == len(input), "expected %d input vertices to be kept exactly, got %d in %v", len(input), found, result)
+}
+
+// TestWithConditioningTiny checks that operands far smaller than the absolute
+// tolerances of the sweep are scaled up in the default mode, on the operands of
+// TestWithConditioning shrunk by a power of two.
+func TestWithConditioningTiny(t *testing.T) {
+	near := 1 - math.Ldexp(1, -27)
+	subject := Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}
+	clipping := Polygon{{{near, -1}, {2, -1}, {2, 2}, {near, 2}}}
+	shrink := Scale(math.Ldexp(1, -260), math.Ldexp(1, -260))
+
+	for _, op := range []Op{UNION, INTERSECTION, DIFFERENCE, XOR} {
+		want := subject.Construct(op, clipping).Transformed(shrink)
+		got := subject.Transformed(shrink).Construct(op, clipping.Transformed(shrink))
+		verify(t, reflect.DeepEqual(got, want), "%v: expected %v, got %v", op, want, got)
+	}
+}
+
+func TestConditionerNearOrigin(t *testing.T) {
+	for _, bb := range []Rectangle{
+		{Point{0, 0}, Point{1, 1}},
+		{Point{-1, 10}, Point{1, 30}},
+		{Point{10, -10}, Point{30, -4}},
+	} {
+		_, ok := newConditioner(CONDITION_TRANSLATE, bb)
+		verify(t, !ok, "%v: expected no conditioning", bb)
+	}
+	cond, ok := newConditioner(CONDITION_TRANSLATE, Rectangle{Point{10, -10}, Point{20, -6}})
+	verify(t, ok && cond.offset.X == 15 && cond.offset.Y == -8, "expected offset {15 -8}, got %v", cond.offset)
+}
+
+func TestWithLinkTolerance(t *testing.T) {
+	subject := Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}}
+	clipping := Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}}}
+	for _, op := range []Op{UNION, INTERSECTION, DIFFERENCE, XOR} {
+		want := subject.Construct(op, clipping)
+		var prov Provenance
+		got := subject.Construct(op, clipping, WithLinkTolerance(1e-9), WithProvenance(&prov))
+		verify(t, reflect.DeepEqual(got, want), "%v: expected %v, got %v", op, want, got)
+		for i := range prov {
+			for j, o := range prov[i] {
+				verify(t, o.Contour >= 0, "%v: no origin for edge %d of contour %d", op, j, i)
+			}
+		}
+	}
+}
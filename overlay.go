@@ -0,0 +1,53 @@
+package polyclip
+
+// OverlayResult holds the three parts of the plane covered by a pair of polygons
+// A and B: the part covered only by A, the part covered only by B, and the part
+// covered by both. Boundaries shared between the parts have identical vertices.
+type OverlayResult struct {
+	AOnly, BOnly, Both Polygon
+}
+
+// Overlay computes A−B, B−A and A∩B in a single sweep. It is equivalent to,
+// but faster than, calling Construct with DIFFERENCE (twice) and INTERSECTION
+// without options: it moves the operands in the same way, and drops contours
+// left open as Construct does without WithLinkTolerance. No options are taken.
+// Like Construct, it is not designed to handle self-intersecting polygons.
+func Overlay(a, b Polygon) OverlayResult {
+	if len(a) == 0 || len(b) == 0 || !a.BoundingBox().Overlaps(b.BoundingBox()) {
+		return OverlayResult{AOnly: a.Clone(), BOnly: b.Clone(), Both: Polygon{}}
+	}
+
+	c := clipper{}
+	operands := c.condition(a, b)
+	c.subject, c.clipping = operands[0], operands[1]
+	c.enqueueOperands(false)
+
+	aOnly := connector{operation: DIFFERENCE}
+	bOnly := connector{operation: DIFFERENCE}
+	both := connector{operation: INTERSECTION}
+	c.sweep(func(*endpoint) bool { return false }, func(e *endpoint) {
+		switch e.edgeType {
+		case _EDGE_NORMAL:
+			if e.other.inside {
+				both.addEdge(e)
+			}
+			if isDifferenceEdge(e, _SUBJECT) {
+				aOnly.addEdge(e)
+			} else {
+				bOnly.addEdge(e)
+			}
+		case _EDGE_SAME_TRANSITION:
+			both.addEdge(e)
+		case _EDGE_DIFFERENT_TRANSITION:
+			aOnly.addEdge(e)
+			bOnly.addEdge(e)
+		}
+	})
+	result := OverlayResult{
+		AOnly: aOnly.toPolygon(),
+		BOnly: bOnly.toPolygon(),
+		Both:  both.toPolygon(),
+	}
+	c.revert(result.AOnly, result.BOnly, result.Both)
+	return result
+}
@@ -0,0 +1,127 @@
+package polyclip
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// area returns the area of a polygon whose edges do not cross each other,
+// counting the points enclosed an odd number of times. Between consecutive x
+// coordinates of vertices, the edges spanning that slab are sorted from bottom
+// to top, and the area between the first and second, the third and fourth, and
+// so on, adds up.
+func area(p Polygon) float64 {
+	var xs []float64
+	var edges []segment
+	for _, c := range p {
+		for i := range c {
+			xs = append(xs, c[i].X)
+			if s := c.segment(i); s.start.X != s.end.X {
+				if s.start.X > s.end.X {
+					s.start, s.end = s.end, s.start
+				}
+				edges = append(edges, s)
+			}
+		}
+	}
+	sort.Float64s(xs)
+
+	var total float64
+	for i := 0; i+1 < len(xs); i++ {
+		x0, x1 := xs[i], xs[i+1]
+		if x0 == x1 {
+			continue
+		}
+		// The heights of the edges spanning the slab at both of its ends.
+		type span struct{ y0, y1 float64 }
+		var spans []span
+		for _, s := range edges {
+			if s.start.X <= x0 && s.end.X >= x1 {
+				at := func(x float64) float64 {
+					return s.start.Y + (x-s.start.X)*(s.end.Y-s.start.Y)/(s.end.X-s.start.X)
+				}
+				spans = append(spans, span{at(x0), at(x1)})
+			}
+		}
+		sort.Slice(spans, func(a, b int) bool { return spans[a].y0+spans[a].y1 < spans[b].y0+spans[b].y1 })
+		for k := 0; k+1 < len(spans); k += 2 {
+			total += (x1 - x0) * (spans[k+1].y0 + spans[k+1].y1 - spans[k].y0 - spans[k].y1) / 2
+		}
+	}
+	return total
+}
+
+func TestOverlay(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b Polygon
+	}{
+		{"overlapping squares",
+			Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}},
+			Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}}}},
+		{"shared edge",
+			Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}},
+			Polygon{{{1, 0}, {2, 0}, {2, 1}, {1, 1}}}},
+		{"contained with hole",
+			Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}, {{1, 1}, {3, 1}, {3, 3}, {1, 3}}},
+			Polygon{{{2, -1}, {5, -1}, {5, 5}, {2, 5}}}},
+		{"disjoint",
+			Polygon{{{0, 0}, {1, 0}, {1, 1}}},
+			Polygon{{{5, 5}, {6, 5}, {6, 6}}}},
+		{"crossing triangles",
+			Polygon{{{0, 0}, {3, 0.1}, {1.3, 2.7}}},
+			Polygon{{{0.2, 1.9}, {0.7, -0.5}, {3.1, 1.3}}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := Overlay(c.a, c.b)
+			for _, x := range []struct {
+				name      string
+				got, want Polygon
+			}{
+				{"A-only", r.AOnly, c.a.Construct(DIFFERENCE, c.b)},
+				{"B-only", r.BOnly, c.b.Construct(DIFFERENCE, c.a)},
+				{"both", r.Both, c.a.Construct(INTERSECTION, c.b)},
+			} {
+				if math.Abs(area(x.got)-area(x.want)) > 1e-9 {
+					t.Errorf("%s: expected area %g (%v), got %g (%v)", x.name, area(x.want), x.want, area(x.got), x.got)
+				}
+			}
+			total := area(r.AOnly) + area(r.BOnly) + area(r.Both)
+			if union := area(c.a.Construct(UNION, c.b)); math.Abs(total-union) > 1e-9 {
+				t.Errorf("expected parts to add up to the union area %g, got %g", union, total)
+			}
+			checkSharedVertices(t, map[string]Polygon{"A-only": r.AOnly, "B-only": r.BOnly, "both": r.Both})
+		})
+	}
+}
+
+// checkSharedVertices checks that where the boundaries of parts meet, they
+// have identical vertices: no vertex of one part lies on an edge of another
+// without being a vertex of it too.
+func checkSharedVertices(t *testing.T, parts map[string]Polygon) {
+	t.Helper()
+	for name, p := range parts {
+		for otherName, other := range parts {
+			if otherName == name {
+				continue
+			}
+			for _, c := range p {
+				for _, pt := range c {
+					for _, oc := range other {
+						for i := range oc {
+							s := oc.segment(i)
+							if pt.Equals(s.start) || pt.Equals(s.end) {
+								continue
+							}
+							if d := distance(pt, s.closestPoint(pt)); d < 1e-9 {
+								t.Errorf("vertex %v of %s lies on edge %v of %s, %g away, but is not a vertex of it", pt, name, s, otherName, d)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
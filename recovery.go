@@ -0,0 +1,234 @@
+package polyclip
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+)
+
+// PanicError is the error a panic of Construct or Simplify is turned into by
+// WithRecovery. It carries the inputs of the operation, so that the panic can
+// be reproduced.
+type PanicError struct {
+	Op                Op   // the operation, unless Simplify is set
+	Simplify          bool // whether Simplify panicked, on Subject alone
+	Subject, Clipping Polygon
+
+	Value interface{} // the value passed to panic
+	Stack []byte      // the stack trace of the panicking goroutine
+
+	// Files lists the reproducer files written by WithReproducer. If they
+	// could not be written, FileErr holds the reason.
+	Files   []string
+	FileErr error
+}
+
+func (e *PanicError) Error() string {
+	op := e.Op.String()
+	if e.Simplify {
+		op = "Simplify"
+	}
+	return fmt.Sprintf("polyclip: %s panicked on %d+%d vertices: %v", op, e.Subject.NumVertices(), e.Clipping.NumVertices(), e.Value)
+}
+
+// Unwrap returns the value passed to panic, if it is an error, such as a
+// runtime.Error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// WithRecovery makes Construct or Simplify recover from a panic and return an
+// empty polygon instead, setting *err to a *PanicError. Otherwise *err is set
+// to nil. Panicking means there is a bug in this package: please report it
+// along with the reproducer from the error, see WithReproducer.
+func WithRecovery(err *error) Option {
+	return func(c *clipper) {
+		c.recovered = err
+	}
+}
+
+// WithReproducer makes Construct or Simplify write two files to dir if a panic
+// is recovered by WithRecovery: the inputs in the text format of the polyutil
+// package, as polyclip-<hash>.txt, and a Go test reproducing the panic, to be
+// added to the tests of this package, as polyclip-<hash>_test.go.txt. The hash
+// identifies the inputs, so that repeated panics do not fill dir.
+func WithReproducer(dir string) Option {
+	return func(c *clipper) {
+		c.reproducerDir = dir
+	}
+}
+
+// recoverPanic turns a panic into a PanicError if requested, setting *result
+// to an empty polygon. It must be deferred by the operation, which is given
+// the operands unchanged.
+func (c *clipper) recoverPanic(simplify bool, op Op, subject, clipping Polygon, result *Polygon) {
+	if c.recovered == nil {
+		return
+	}
+	v := recover()
+	if v == nil {
+		*c.recovered = nil
+		return
+	}
+	e := &PanicError{
+		Op:       op,
+		Simplify: simplify,
+		Subject:  subject.Clone(),
+		Clipping: clipping.Clone(),
+		Value:    v,
+		Stack:    debug.Stack(),
+	}
+	if c.reproducerDir != "" {
+		e.Files, e.FileErr = e.writeReproducer(c.reproducerDir)
+	}
+	*result = Polygon{}
+	*c.recovered = e
+}
+
+// writeReproducer writes the reproducer files of e to dir, returning their paths.
+func (e *PanicError) writeReproducer(dir string) ([]string, error) {
+	var text, test bytes.Buffer
+	if err := e.WriteText(&text); err != nil {
+		return nil, err
+	}
+	h := fnv.New64a()
+	h.Write(text.Bytes())
+	name := fmt.Sprintf("polyclip-%016x", h.Sum64())
+	if err := e.WriteGoTest(&test, "TestPanic"+name[len("polyclip-"):]); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{name + ".txt", text.Bytes()},
+		{name + "_test.go.txt", test.Bytes()},
+	} {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, f.data, 0o644); err != nil {
+			return files, err
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// WriteText writes the inputs of the panicking operation to w: the subject and
+// clipping polygons as written by polyutil.EncodePolygon, so that two calls of
+// polyutil.DecodePolygon read them back, followed by a line naming the
+// operation (one of the Op constants, or SIMPLIFY). The clipping polygon of
+// Simplify is empty.
+func (e *PanicError) WriteText(w io.Writer) error {
+	for _, p := range []Polygon{e.Subject, e.Clipping} {
+		if _, err := fmt.Fprint(w, len(p), "\n"); err != nil {
+			return err
+		}
+		for _, c := range p {
+			if _, err := fmt.Fprint(w, len(c), " 1\n"); err != nil {
+				return err
+			}
+			for _, pt := range c {
+				if _, err := fmt.Fprint(w, "\t", pt.X, " ", pt.Y, "\n"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	op := e.Op.String()
+	if e.Simplify {
+		op = "SIMPLIFY"
+	}
+	_, err := fmt.Fprintln(w, op)
+	return err
+}
+
+// WriteGoTest writes to w a test function with the given name, in the style
+// of bugs_test.go in this package, which calls the panicking operation on its
+// inputs. The test fails if the operation panics or loses geometry, as
+// reported by WithDiagnostics, or if edges of a polygon result cross. The
+// coordinates are written exactly.
+func (e *PanicError) WriteGoTest(w io.Writer, name string) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n", name)
+	fmt.Fprintf(&b, "\t// Panicked with: %s\n", strconv.Quote(fmt.Sprint(e.Value)))
+	writeGoPolygon(&b, "subject", e.Subject)
+	const opts = "polyclip.WithRecovery(&err), polyclip.WithDiagnostics(&diag)"
+	b.WriteString("\n\tvar err error\n\tvar diag polyclip.Diagnostics\n")
+	if e.Simplify {
+		fmt.Fprintf(&b, "\tresult := subject.Simplify(%s)\n", opts)
+	} else {
+		writeGoPolygon(&b, "clipping", e.Clipping)
+		fmt.Fprintf(&b, "\tresult := subject.Construct(polyclip.%v, clipping, %s)\n", e.Op, opts)
+	}
+	b.WriteString(`	if err != nil {
+		t.Fatal(err)
+	}
+	if !diag.Empty() {
+		t.Errorf("lost geometry: %+v", diag)
+	}
+`)
+	if e.Simplify || e.Op != CLIPLINE {
+		b.WriteString(`	var edges []polyclip.Segment
+	for _, c := range result {
+		if len(c) < 3 {
+			t.Errorf("degenerate contour %v", c)
+		}
+		for i := range c {
+			edges = append(edges, polyclip.Segment{Start: c[i], End: c[(i+1)%len(c)]})
+		}
+	}
+	for _, x := range polyclip.Intersections(edges) {
+		if x.Kind == polyclip.CROSSING {
+			t.Errorf("edges %v and %v of the result cross at %v", edges[x.I], edges[x.J], x.Point)
+		}
+	}
+`)
+	} else {
+		b.WriteString("\t_ = result\n")
+	}
+	b.WriteString("}\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func writeGoPolygon(b *bytes.Buffer, name string, p Polygon) {
+	fmt.Fprintf(b, "\t%s := polyclip.Polygon{", name)
+	for _, c := range p {
+		b.WriteString("polyclip.Contour{\n")
+		for _, pt := range c {
+			fmt.Fprintf(b, "\t\tpolyclip.Point{X: %s, Y: %s},\n", goFloat(pt.X), goFloat(pt.Y))
+		}
+		b.WriteString("\t}, ")
+	}
+	if len(p) > 0 {
+		b.Truncate(b.Len() - 2)
+	}
+	b.WriteString("}\n")
+}
+
+// goFloat formats v as a Go expression evaluating to exactly v.
+func goFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "math.NaN()"
+	case math.IsInf(v, 1):
+		return "math.Inf(1)"
+	case math.IsInf(v, -1):
+		return "math.Inf(-1)"
+	case v == 0 && math.Signbit(v):
+		return "math.Copysign(0, -1)"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,128 @@
+package polyclip_test
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	polyclip "github.com/ctessum/polyclip-go"
+	"github.com/ctessum/polyclip-go/polyutil"
+)
+
+var errBoom = errors.New("boom")
+
+// panicTracer panics on the first intersection found.
+type panicTracer struct{}
+
+func (panicTracer) Trace(e polyclip.TraceEvent) {
+	if e.Kind == polyclip.TRACE_INTERSECTION {
+		panic(errBoom)
+	}
+}
+
+var (
+	recoverySubject  = polyclip.Polygon{{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}}}
+	recoveryClipping = polyclip.Polygon{{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 3}, {X: 1, Y: 3.000000000000001}}}
+)
+
+func TestWithRecovery(t *testing.T) {
+	err := errors.New("not reset")
+	result := recoverySubject.Construct(polyclip.UNION, recoveryClipping, polyclip.WithRecovery(&err))
+	if err != nil || len(result) != 1 {
+		t.Errorf("expected a result without error, got %v, %v", result, err)
+	}
+
+	result = recoverySubject.Construct(polyclip.XOR, recoveryClipping, polyclip.WithRecovery(&err), polyclip.WithTracer(panicTracer{}))
+	var pe *polyclip.PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected a PanicError, got %v", err)
+	}
+	if !errors.Is(err, errBoom) {
+		t.Errorf("expected the error to wrap the panic value, got %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("expected an empty result, got %v", result)
+	}
+	if pe.Op != polyclip.XOR || pe.Simplify || dump(pe.Subject) != dump(recoverySubject) || dump(pe.Clipping) != dump(recoveryClipping) {
+		t.Errorf("expected the operation and its operands, got %v %v %v", pe.Op, pe.Subject, pe.Clipping)
+	}
+	if want := "polyclip: XOR panicked on 4+4 vertices: boom"; err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+	if len(pe.Stack) == 0 || len(pe.Files) != 0 {
+		t.Errorf("expected a stack trace and no files, got %d bytes, %v", len(pe.Stack), pe.Files)
+	}
+}
+
+func TestWithoutRecovery(t *testing.T) {
+	defer func() {
+		if v := recover(); v != errBoom {
+			t.Errorf("expected the panic to propagate, got %v", v)
+		}
+	}()
+	recoverySubject.Construct(polyclip.UNION, recoveryClipping, polyclip.WithTracer(panicTracer{}))
+}
+
+func TestWithReproducer(t *testing.T) {
+	dir := t.TempDir()
+	var err error
+	recoverySubject.Construct(polyclip.DIFFERENCE, recoveryClipping,
+		polyclip.WithRecovery(&err), polyclip.WithReproducer(dir), polyclip.WithTracer(panicTracer{}))
+	pe := err.(*polyclip.PanicError)
+	if pe.FileErr != nil || len(pe.Files) != 2 {
+		t.Fatalf("expected two files, got %v, %v", pe.Files, pe.FileErr)
+	}
+
+	// The inputs can be read back exactly.
+	f, ferr := os.Open(pe.Files[0])
+	if ferr != nil {
+		t.Fatal(ferr)
+	}
+	defer f.Close()
+	subject, serr := polyutil.DecodePolygon(f)
+	clipping, cerr := polyutil.DecodePolygon(f)
+	var op string
+	fmt.Fscan(f, &op)
+	if op != "DIFFERENCE" || serr != nil || cerr != nil {
+		t.Fatalf("expected two polygons and DIFFERENCE, got %v, %v, %q", serr, cerr, op)
+	}
+	if dump(*subject) != dump(recoverySubject) || dump(*clipping) != dump(recoveryClipping) {
+		t.Errorf("expected the operands, got %v and %v", *subject, *clipping)
+	}
+
+	// The test is valid Go, and calls the operation.
+	src, ferr := os.ReadFile(pe.Files[1])
+	if ferr != nil {
+		t.Fatal(ferr)
+	}
+	if _, perr := parser.ParseFile(token.NewFileSet(), "", "package p\n"+string(src), 0); perr != nil {
+		t.Errorf("expected valid Go, got %v:\n%s", perr, src)
+	}
+	for _, want := range []string{"polyclip.Point{X: 1, Y: 3.000000000000001},", "subject.Construct(polyclip.DIFFERENCE, clipping, polyclip.WithRecovery(&err), polyclip.WithDiagnostics(&diag))",
+		"t.Fatal(err)", "polyclip.Intersections(edges)", `// Panicked with: "boom"`} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected %q in:\n%s", want, src)
+		}
+	}
+}
+
+func TestSimplifyWithRecovery(t *testing.T) {
+	var err error
+	bowtie := polyclip.Polygon{{{X: 0, Y: 0}, {X: 2, Y: 2}, {X: 2, Y: 0}, {X: 0, Y: 2}}}
+	bowtie.Simplify(polyclip.WithRecovery(&err), polyclip.WithTracer(panicTracer{}))
+	pe, ok := err.(*polyclip.PanicError)
+	if !ok || !pe.Simplify || !strings.HasPrefix(pe.Error(), "polyclip: Simplify panicked") {
+		t.Fatalf("expected a PanicError from Simplify, got %v", err)
+	}
+	var b strings.Builder
+	if werr := pe.WriteGoTest(&b, "TestBowtie"); werr != nil {
+		t.Fatal(werr)
+	}
+	if !strings.Contains(b.String(), "func TestBowtie(t *testing.T) {") || !strings.Contains(b.String(), "subject.Simplify(polyclip.WithRecovery(&err), polyclip.WithDiagnostics(&diag))") {
+		t.Errorf("expected a test calling Simplify, got:\n%s", b.String())
+	}
+}
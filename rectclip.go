@@ -0,0 +1,306 @@
+package polyclip
+
+import (
+	"math"
+	"sort"
+)
+
+// ClipToRect computes the intersection of p with the rectangle r. The result has
+// the same area as that of Construct with INTERSECTION and a rectangular clipping
+// polygon, but is computed without a sweep, clipping each contour against the
+// four sides of r in turn (the Sutherland–Hodgman algorithm) in time linear in
+// its vertices. Connecting the n clipped edges into contours then takes time
+// O(n log n + n·m), where m is the largest number of chains open at once, as
+// in Construct.
+//
+// Clipping a concave contour this way can leave pairs of edges running back and
+// forth along the border of r, connecting the parts of the contour inside of it.
+// These are removed, so that such parts become separate contours.
+func (p Polygon) ClipToRect(r Rectangle) Polygon {
+	if len(p) == 0 || !p.BoundingBox().Overlaps(r) {
+		return Polygon{}
+	}
+	if bb := p.BoundingBox(); bb.union(r) == r {
+		return p.Clone()
+	}
+
+	clipped := make([]Contour, len(p))
+	for i, c := range p {
+		for _, side := range rectSides(r) {
+			c = side.clip(c)
+		}
+		clipped[i] = c
+	}
+	return connectClipped(clipped, r)
+}
+
+// connectClipped forms the polygon made up of contours clipped to r, which may
+// run back and forth along its sides, by dropping the parts of the sides they
+// cover an even number of times and connecting the remaining edges.
+func connectClipped(contours []Contour, r Rectangle) Polygon {
+	var inner, border []segment
+	for _, c := range contours {
+		for i := range c {
+			s := c.segment(i)
+			switch {
+			case s.start.Equals(s.end):
+			case onBorder(s, r):
+				border = append(border, s)
+			default:
+				inner = append(inner, s)
+			}
+		}
+	}
+
+	conn := connector{operation: INTERSECTION}
+	for _, s := range inner {
+		conn.add(s)
+	}
+	for _, s := range cancelBorderSegments(border, inner) {
+		conn.add(s)
+	}
+	return conn.toPolygon()
+}
+
+// ClipLineToRect clips the line strings making up p to the rectangle r, like
+// Construct with CLIPLINE and a rectangular clipping polygon, but in linear time
+// using the Liang–Barsky algorithm. Parts of the lines on the border of r are kept.
+func (p Polygon) ClipLineToRect(r Rectangle) Polygon {
+	result := Polygon{}
+	for _, c := range p {
+		var line Contour
+		for i := 0; i+1 < len(c); i++ {
+			s := c.segment(i)
+			if s.start.Equals(s.end) {
+				continue
+			}
+			clipped, ok := clipSegmentToRect(s, r)
+			if !ok || clipped.start.Equals(clipped.end) {
+				if len(line) > 0 {
+					result.Add(line)
+					line = nil
+				}
+				continue
+			}
+			if len(line) > 0 && !line[len(line)-1].Equals(clipped.start) {
+				result.Add(line)
+				line = nil
+			}
+			if len(line) == 0 {
+				line.Add(clipped.start)
+			}
+			line.Add(clipped.end)
+		}
+		if len(line) > 0 {
+			result.Add(line)
+		}
+	}
+	return result
+}
+
+// rectSide is one of the half planes whose intersection makes up a rectangle:
+// the points whose coordinate on the given axis is at least (or, if max is
+// set, at most) value.
+type rectSide struct {
+	axis  int // 0 for X, 1 for Y
+	value float64
+	max   bool
+}
+
+func rectSides(r Rectangle) [4]rectSide {
+	return [4]rectSide{{0, r.Min.X, false}, {0, r.Max.X, true}, {1, r.Min.Y, false}, {1, r.Max.Y, true}}
+}
+
+func coord(p Point, axis int) float64 {
+	if axis == 0 {
+		return p.X
+	}
+	return p.Y
+}
+
+func (s rectSide) inside(p Point) bool {
+	if s.max {
+		return coord(p, s.axis) <= s.value
+	}
+	return coord(p, s.axis) >= s.value
+}
+
+// crossing returns the point where the segment from a to b crosses the side's
+// border line. The point lies exactly on the line, so that border edges of the
+// clipped contours can be recognized, and the other coordinate is kept between
+// those of a and b despite rounding.
+func (s rectSide) crossing(a, b Point) Point {
+	t := (s.value - coord(a, s.axis)) / (coord(b, s.axis) - coord(a, s.axis))
+	other := 1 - s.axis
+	lo, hi := math.Min(coord(a, other), coord(b, other)), math.Max(coord(a, other), coord(b, other))
+	v := math.Max(lo, math.Min(hi, coord(a, other)+t*(coord(b, other)-coord(a, other))))
+	if s.axis == 0 {
+		return Point{s.value, v}
+	}
+	return Point{v, s.value}
+}
+
+// clip returns the part of contour c inside of s.
+func (s rectSide) clip(c Contour) Contour {
+	var result Contour
+	for i := range c {
+		cur, next := c.segment(i).start, c.segment(i).end
+		switch curIn, nextIn := s.inside(cur), s.inside(next); {
+		case curIn && nextIn:
+			result.Add(next)
+		case curIn:
+			result.Add(s.crossing(cur, next))
+		case nextIn:
+			result.Add(s.crossing(cur, next))
+			result.Add(next)
+		}
+	}
+	return result
+}
+
+// onBorder returns whether s runs along one of the sides of r.
+func onBorder(s segment, r Rectangle) bool {
+	for _, side := range rectSides(r) {
+		if coord(s.start, side.axis) == side.value && coord(s.end, side.axis) == side.value {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelBorderSegments removes the parts of border that are covered an even
+// number of times: there the clipped polygon lies on neither side of the border,
+// or on both. The remaining parts are merged, except at endpoints of other
+// segments, which the result must still connect to.
+func cancelBorderSegments(border, inner []segment) []segment {
+	type line struct {
+		axis  int
+		value float64
+	}
+	byLine := make(map[line][]segment)
+	var lines []line
+	for _, s := range border {
+		axis := 0
+		if s.start.Y == s.end.Y {
+			axis = 1
+		}
+		l := line{axis, coord(s.start, axis)}
+		if _, ok := byLine[l]; !ok {
+			lines = append(lines, l)
+		}
+		byLine[l] = append(byLine[l], s)
+	}
+
+	// For every endpoint, the lines it lies on the border segments of, by their
+	// index in lines, and whether it is an endpoint of an inner segment. There
+	// are at most four lines, one for each side of the rectangle.
+	const innerJoint = 1 << 4
+	joints := make(map[Point]uint8)
+	for _, s := range inner {
+		joints[s.start] |= innerJoint
+		joints[s.end] |= innerJoint
+	}
+	for i, l := range lines {
+		for _, s := range byLine[l] {
+			joints[s.start] |= 1 << i
+			joints[s.end] |= 1 << i
+		}
+	}
+
+	var result []segment
+	for i, l := range lines {
+		segs := byLine[l]
+		// Endpoints of segments not on this line, including those on other sides
+		// of the rectangle meeting it at a corner.
+		isJoint := func(p Point) bool { return joints[p]&^(1<<i) != 0 }
+
+		// Going along the line, every endpoint toggles whether the part
+		// beyond it is covered an odd number of times.
+		along := func(p Point) float64 { return coord(p, 1-l.axis) }
+		var ps []Point
+		for _, s := range segs {
+			ps = append(ps, s.start, s.end)
+		}
+		sort.Slice(ps, func(i, j int) bool { return along(ps[i]) < along(ps[j]) })
+		var start *Point
+		for k := 0; k+1 < len(ps); k++ {
+			a, b := ps[k], ps[k+1]
+			if along(a) == along(b) {
+				continue
+			}
+			odd := k%2 == 0 // k+1 endpoints lie at or before a
+			switch {
+			case odd && start == nil:
+				start = &ps[k]
+			case !odd && start != nil:
+				result = append(result, segment{*start, a})
+				start = nil
+			}
+			if start != nil && isJoint(b) {
+				result = append(result, segment{*start, b})
+				start = nil
+			}
+		}
+		if start != nil {
+			result = append(result, segment{*start, ps[len(ps)-1]})
+		}
+	}
+	return result
+}
+
+// clipSegmentToRect returns the part of s within r, if any.
+func clipSegmentToRect(s segment, r Rectangle) (segment, bool) {
+	dx, dy := s.end.X-s.start.X, s.end.Y-s.start.Y
+	t0, t1 := 0., 1.
+	// For each side, p*t <= q must hold for points inside of it.
+	for _, pq := range [4][2]float64{
+		{-dx, s.start.X - r.Min.X},
+		{dx, r.Max.X - s.start.X},
+		{-dy, s.start.Y - r.Min.Y},
+		{dy, r.Max.Y - s.start.Y},
+	} {
+		p, q := pq[0], pq[1]
+		switch {
+		case p == 0:
+			if q < 0 {
+				return segment{}, false
+			}
+		case p < 0:
+			if t := q / p; t > t0 {
+				t0 = t
+			}
+		default:
+			if t := q / p; t < t1 {
+				t1 = t
+			}
+		}
+	}
+	if t0 > t1 {
+		return segment{}, false
+	}
+	at := func(t float64) Point {
+		switch t {
+		case 0:
+			return s.start
+		case 1:
+			return s.end
+		}
+		return clampToRect(Point{s.start.X + t*dx, s.start.Y + t*dy}, r)
+	}
+	return segment{at(t0), at(t1)}, true
+}
+
+// clampToRect moves p, known to lie on r up to rounding errors, into r.
+func clampToRect(p Point, r Rectangle) Point {
+	if p.X < r.Min.X {
+		p.X = r.Min.X
+	} else if p.X > r.Max.X {
+		p.X = r.Max.X
+	}
+	if p.Y < r.Min.Y {
+		p.Y = r.Min.Y
+	} else if p.Y > r.Max.Y {
+		p.Y = r.Max.Y
+	}
+	return p
+}
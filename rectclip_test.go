@@ -0,0 +1,79 @@
+package polyclip
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClipToRect(t *testing.T) {
+	r := Rectangle{Point{0, 0}, Point{4, 4}}
+	// A comb whose teeth reach into r from below, joined outside of it.
+	comb := Polygon{{{-1, -2}, {5, -2}, {5, 2}, {4.5, 2}, {4.5, -1}, {3, -1}, {3, 2}, {2, 2}, {2, -1}, {1, -1}, {1, 2}, {-1, 2}}}
+	star := Polygon{{}}
+	for i := 0; i < 10; i++ {
+		radius := 5.
+		if i%2 == 1 {
+			radius = 2
+		}
+		angle := math.Pi * float64(i) / 5
+		star[0].Add(Point{2 + radius*math.Cos(angle), 2 + radius*math.Sin(angle)})
+	}
+	cases := []struct {
+		name     string
+		p        Polygon
+		contours int
+	}{
+		{"inside", Polygon{{{1, 1}, {2, 1}, {2, 2}}}, 1},
+		{"outside", Polygon{{{5, 5}, {6, 5}, {6, 6}}}, 0},
+		{"covering", Polygon{{{-1, -1}, {5, -1}, {5, 5}, {-1, 5}}}, 1},
+		{"crossing corner", Polygon{{{2, 2}, {6, 2}, {6, 6}, {2, 6}}}, 1},
+		{"comb", comb, 2},
+		{"hole", Polygon{{{-1, -1}, {5, -1}, {5, 5}, {-1, 5}}, {{1, 1}, {3, 1}, {3, 3}, {1, 3}}}, 2},
+		{"hole crossing border", Polygon{{{-1, -1}, {5, -1}, {5, 5}, {-1, 5}}, {{3, 1}, {6, 1}, {6, 3}, {3, 3}}}, 1},
+		{"star", star, 1},
+		{"diamond", Polygon{{{2, -1}, {5, 2}, {2, 5}, {-1, 2}}}, 1},
+	}
+	rect := Polygon{{r.Min, {r.Max.X, r.Min.Y}, r.Max, {r.Min.X, r.Max.Y}}}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.p.ClipToRect(r)
+			want := c.p.Construct(INTERSECTION, rect)
+			verify(t, circa(area(got), area(want)), "expected area %g, got %g (%v)", area(want), area(got), got)
+			verify(t, len(got) == c.contours, "expected %d contours, got %v", c.contours, got)
+			for _, con := range got {
+				for i := range con {
+					s := con.segment(i)
+					verify(t, !s.start.Equals(s.end), "unexpected zero-length edge in %v", con)
+					verify(t, s.start.X >= 0 && s.start.X <= 4 && s.start.Y >= 0 && s.start.Y <= 4, "point %v outside of rectangle", s.start)
+				}
+			}
+		})
+	}
+}
+
+func TestClipLineToRect(t *testing.T) {
+	r := Rectangle{Point{0, 0}, Point{4, 4}}
+	cases := []struct {
+		name      string
+		line, out Polygon
+	}{
+		{"inside", Polygon{{{1, 1}, {2, 2}, {3, 1}}}, Polygon{{{1, 1}, {2, 2}, {3, 1}}}},
+		{"outside", Polygon{{{5, 5}, {6, 6}}}, Polygon{}},
+		{"through", Polygon{{{-2, 2}, {6, 2}}}, Polygon{{{0, 2}, {4, 2}}}},
+		{"in and out twice", Polygon{{{-1, 1}, {2, 1}, {2, 6}, {3, 6}, {3, 3}, {5, 3}}}, Polygon{{{0, 1}, {2, 1}, {2, 4}}, {{3, 4}, {3, 3}, {4, 3}}}},
+		{"along border", Polygon{{{-1, 0}, {5, 0}}}, Polygon{{{0, 0}, {4, 0}}}},
+		{"touching corner", Polygon{{{-1, 1}, {1, -1}}}, Polygon{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.line.ClipLineToRect(r)
+			verify(t, len(got) == len(c.out), "expected %v, got %v", c.out, got)
+			for i := 0; i < len(got) && i < len(c.out); i++ {
+				verify(t, len(got[i]) == len(c.out[i]), "expected %v, got %v", c.out, got)
+				for j := 0; j < len(got[i]) && j < len(c.out[i]); j++ {
+					verify(t, pointsCirca(got[i][j], c.out[i][j]), "expected %v, got %v", c.out, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package polyclip
+
+// Dimension is the dimension of the intersection of two point sets.
+type Dimension int
+
+const (
+	EMPTY Dimension = iota - 1
+	POINT
+	LINE
+	AREA
+)
+
+// Parts of a polygon, as used to index a Matrix.
+const (
+	INTERIOR = iota
+	BOUNDARY
+	EXTERIOR
+)
+
+// Matrix is a DE-9IM intersection matrix describing the spatial relationship of
+// two polygons a and b: Matrix[i][j] is the dimension of the intersection of
+// part i of a with part j of b, where parts are INTERIOR, BOUNDARY and EXTERIOR.
+type Matrix [3][3]Dimension
+
+// String returns the matrix in the usual nine-character notation, e.g. "212101212".
+func (m Matrix) String() string {
+	s := make([]byte, 0, 9)
+	for i := range m {
+		for _, d := range m[i] {
+			if d == EMPTY {
+				s = append(s, 'F')
+			} else {
+				s = append(s, byte('0'+d))
+			}
+		}
+	}
+	return string(s)
+}
+
+// Matches returns whether m matches a nine-character DE-9IM pattern, where
+// each character is one of 'T' (non-empty), 'F' (empty), '*' (anything),
+// or '0', '1' and '2' (a specific dimension).
+func (m Matrix) Matches(pattern string) bool {
+	result, _ := m.decide(pattern, true)
+	return result
+}
+
+// decide matches m against pattern. Unless final is set, m may still grow as
+// more of the polygons is processed, and known reports whether the result
+// can no longer change.
+func (m Matrix) decide(pattern string, final bool) (result, known bool) {
+	if len(pattern) != 9 {
+		return false, true
+	}
+	conclusive := true
+	for k := 0; k < 9; k++ {
+		d := m[k/3][k%3]
+		switch c := pattern[k]; c {
+		case '*':
+		case 'T', 't':
+			if d == EMPTY {
+				if final {
+					return false, true
+				}
+				conclusive = false
+			}
+		case 'F', 'f':
+			if d != EMPTY {
+				return false, true
+			}
+			conclusive = false // a later part of the sweep might fill the entry
+		case '0', '1', '2':
+			want := Dimension(c - '0')
+			if d > want || final && d != want {
+				return false, true
+			}
+			conclusive = conclusive && d == want && want == AREA
+		default:
+			return false, true
+		}
+	}
+	return conclusive || final, conclusive || final
+}
+
+// Relate computes the DE-9IM matrix describing the spatial relationship of a and b.
+// It shares the sweep of Construct, but does not build any result geometry.
+// Like Construct, it is not designed to handle self-intersecting polygons.
+func Relate(a, b Polygon) Matrix {
+	return relate(a, b, nil)
+}
+
+// Intersects returns whether a and b have at least one point in common.
+func Intersects(a, b Polygon) bool {
+	return relateMatches(a, b, "T********", "*T*******", "***T*****", "****T****")
+}
+
+// Disjoint returns whether a and b have no point in common.
+func Disjoint(a, b Polygon) bool {
+	return !Intersects(a, b)
+}
+
+// Touches returns whether a and b have at least one boundary point in common,
+// but no interior points.
+func Touches(a, b Polygon) bool {
+	return relateMatches(a, b, "FT*******", "F**T*****", "F***T****")
+}
+
+// Contains returns whether no points of b lie in the exterior of a, and at
+// least one point of the interior of b lies in the interior of a.
+func Contains(a, b Polygon) bool {
+	return relateMatches(a, b, "T*****FF*")
+}
+
+// Within returns whether a lies within b; see Contains.
+func Within(a, b Polygon) bool {
+	return relateMatches(a, b, "T*F**F***")
+}
+
+// Covers returns whether no points of b lie in the exterior of a.
+func Covers(a, b Polygon) bool {
+	return relateMatches(a, b, "T*****FF*", "*T****FF*", "***T**FF*", "****T*FF*")
+}
+
+// Overlaps returns whether a and b have some but not all interior points in common.
+func Overlaps(a, b Polygon) bool {
+	return relateMatches(a, b, "T*T***T**")
+}
+
+// relateMatches returns whether the DE-9IM matrix of a and b matches any of
+// the patterns, stopping the sweep as soon as the answer is known.
+func relateMatches(a, b Polygon, patterns ...string) bool {
+	m := relate(a, b, func(m *Matrix) bool {
+		for _, p := range patterns {
+			result, known := m.decide(p, false)
+			if !known || result {
+				return known
+			}
+		}
+		return true // all patterns are known not to match
+	})
+	for _, p := range patterns {
+		if m.Matches(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// relate computes the DE-9IM matrix of a and b. If decided is not nil, it is
+// called whenever the matrix changes, and the computation stops once it returns true.
+func relate(a, b Polygon, decided func(m *Matrix) bool) Matrix {
+	var m Matrix
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = EMPTY
+		}
+	}
+	m[EXTERIOR][EXTERIOR] = AREA
+
+	if len(a) == 0 || len(b) == 0 || !a.BoundingBox().Overlaps(b.BoundingBox()) {
+		if len(a) > 0 {
+			m[INTERIOR][EXTERIOR], m[BOUNDARY][EXTERIOR] = AREA, LINE
+		}
+		if len(b) > 0 {
+			m[EXTERIOR][INTERIOR], m[EXTERIOR][BOUNDARY] = AREA, LINE
+		}
+		return m
+	}
+
+	set := func(i, j int, d Dimension) {
+		if m[i][j] < d {
+			m[i][j] = d
+		}
+	}
+
+	// The matrix only depends on which points are equal, which moving the
+	// operands preserves, so nothing needs to be mapped back.
+	c := clipper{}
+	operands := c.condition(a, b)
+	c.subject, c.clipping = operands[0], operands[1]
+	c.enqueueOperands(false)
+
+	// Endpoints of the boundary segments of each polygon, to find boundary
+	// points the polygons have in common.
+	var vertices [2]map[Point]bool
+	vertices[_SUBJECT], vertices[_CLIPPING] = make(map[Point]bool), make(map[Point]bool)
+	stop := false
+	c.sweep(func(*endpoint) bool { return stop }, func(e *endpoint) {
+		before := m
+		for _, p := range []Point{e.p, e.other.p} {
+			vertices[e.polygonType][p] = true
+			if vertices[1-e.polygonType][p] {
+				set(BOUNDARY, BOUNDARY, POINT)
+			}
+		}
+
+		switch e.edgeType {
+		case _EDGE_NORMAL:
+			// The parts of a (or b) on either side of the segment are in the interior
+			// or exterior of the polygon, and both lie in the same part of the other polygon.
+			part := EXTERIOR
+			if e.other.inside {
+				part = INTERIOR
+			}
+			if e.polygonType == _SUBJECT {
+				set(BOUNDARY, part, LINE)
+				set(INTERIOR, part, AREA)
+				set(EXTERIOR, part, AREA)
+			} else {
+				set(part, BOUNDARY, LINE)
+				set(part, INTERIOR, AREA)
+				set(part, EXTERIOR, AREA)
+			}
+		case _EDGE_SAME_TRANSITION:
+			// The polygons share the segment, and lie on the same side of it.
+			set(BOUNDARY, BOUNDARY, LINE)
+			set(INTERIOR, INTERIOR, AREA)
+		case _EDGE_DIFFERENT_TRANSITION:
+			// The polygons share the segment, and lie on opposite sides of it.
+			set(BOUNDARY, BOUNDARY, LINE)
+			set(INTERIOR, EXTERIOR, AREA)
+			set(EXTERIOR, INTERIOR, AREA)
+		}
+
+		if decided != nil && m != before && decided(&m) {
+			stop = true
+		}
+	})
+	return m
+}
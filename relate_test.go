@@ -0,0 +1,65 @@
+package polyclip
+
+import "testing"
+
+func TestRelate(t *testing.T) {
+	square := func(x, y, size float64) Polygon {
+		return Polygon{{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}}}
+	}
+	cases := []struct {
+		name   string
+		a, b   Polygon
+		matrix string
+		// expected results of Intersects, Touches, Contains, Within, Covers, Overlaps
+		intersects, touches, contains, within, covers, overlaps bool
+	}{
+		{"disjoint", square(0, 0, 1), square(5, 5, 1), "FF2FF1212",
+			false, false, false, false, false, false},
+		{"disjoint, overlapping boxes", Polygon{{{0, 0}, {4, 0}, {0, 4}}}, square(3, 3, 1), "FF2FF1212",
+			false, false, false, false, false, false},
+		{"shared edge", square(0, 0, 1), square(1, 0, 1), "FF2F11212",
+			true, true, false, false, false, false},
+		{"shared corner", square(0, 0, 1), square(1, 1, 1), "FF2F01212",
+			true, true, false, false, false, false},
+		{"overlapping", square(0, 0, 2), square(1, 1, 2), "212101212",
+			true, false, false, false, false, true},
+		{"contains", square(0, 0, 4), square(1, 1, 2), "212FF1FF2",
+			true, false, true, false, true, false},
+		{"within", square(1, 1, 2), square(0, 0, 4), "2FF1FF212",
+			true, false, false, true, false, false},
+		{"equal", square(0, 0, 1), square(0, 0, 1), "2FFF1FFF2",
+			true, false, true, true, true, false},
+		{"covers with shared edge", square(0, 0, 4), square(0, 0, 2), "212F11FF2",
+			true, false, true, false, true, false},
+		{"in hole", Polygon{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}, {{1, 1}, {3, 1}, {3, 3}, {1, 3}}}, square(1.5, 1.5, 1), "FF2FF1212",
+			false, false, false, false, false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := Relate(c.a, c.b)
+			verify(t, m.String() == c.matrix, "expected matrix %s, got %s", c.matrix, m)
+			verify(t, m.Matches(c.matrix), "expected %s to match itself", m)
+			verify(t, Intersects(c.a, c.b) == c.intersects, "Intersects: expected %v", c.intersects)
+			verify(t, Disjoint(c.a, c.b) == !c.intersects, "Disjoint: expected %v", !c.intersects)
+			verify(t, Touches(c.a, c.b) == c.touches, "Touches: expected %v", c.touches)
+			verify(t, Contains(c.a, c.b) == c.contains, "Contains: expected %v", c.contains)
+			verify(t, Within(c.a, c.b) == c.within, "Within: expected %v", c.within)
+			verify(t, Covers(c.a, c.b) == c.covers, "Covers: expected %v", c.covers)
+			verify(t, Overlaps(c.a, c.b) == c.overlaps, "Overlaps: expected %v", c.overlaps)
+		})
+	}
+}
+
+func TestMatrixMatches(t *testing.T) {
+	m := Matrix{{AREA, LINE, AREA}, {LINE, POINT, LINE}, {AREA, LINE, AREA}}
+	for pattern, want := range map[string]bool{
+		"212101212": true,
+		"T*T***T**": true,
+		"T********": true,
+		"F********": false,
+		"21210121":  false,
+		"212111212": false,
+	} {
+		verify(t, m.Matches(pattern) == want, "%s.Matches(%s): expected %v", m, pattern, want)
+	}
+}
@@ -0,0 +1,283 @@
+// Package rtree provides a static spatial index of rectangles, a packed R-tree
+// bulk loaded with the Sort-Tile-Recursive (STR) algorithm. It is meant for
+// finding the few polygons of a large collection that may interact with a given
+// one, before calling Construct on them:
+//
+//	tree := rtree.FromPolygons(polys, 0)
+//	for it := tree.Search(p.BoundingBox()); it.Next(); {
+//		result := p.Construct(polyclip.INTERSECTION, polys[it.ID()])
+//		...
+//	}
+package rtree
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/ctessum/polyclip-go"
+)
+
+// DefaultNodeSize is the number of entries per node used when none is given.
+const DefaultNodeSize = 16
+
+// Item is a rectangle to index, together with an ID identifying it to the
+// caller, such as its index in a slice of polygons.
+type Item struct {
+	Box polyclip.Rectangle
+	ID  int
+}
+
+// Tree is a packed R-tree. It cannot be modified after it has been built, but
+// can be searched from several goroutines at the same time.
+type Tree struct {
+	nodeSize int
+	// The entries of all levels of the tree, from the items at the leaves up to the root.
+	// For items, ref is the item ID; for nodes, the position of their first child.
+	boxes []polyclip.Rectangle
+	refs  []int
+	// levelEnd[l] is the position after the last entry of level l.
+	levelEnd []int
+}
+
+type entry struct {
+	box polyclip.Rectangle
+	ref int
+}
+
+// New builds a tree holding items, with at most nodeSize entries per node.
+// If nodeSize is less than 2, DefaultNodeSize is used.
+func New(items []Item, nodeSize int) *Tree {
+	if nodeSize < 2 {
+		nodeSize = DefaultNodeSize
+	}
+	t := &Tree{nodeSize: nodeSize}
+	level := make([]entry, len(items))
+	for i, it := range items {
+		level[i] = entry{it.Box, it.ID}
+	}
+	for {
+		sortTileRecursive(level, nodeSize)
+		start := len(t.boxes)
+		for _, e := range level {
+			t.boxes = append(t.boxes, e.box)
+			t.refs = append(t.refs, e.ref)
+		}
+		t.levelEnd = append(t.levelEnd, len(t.boxes))
+		if len(level) <= 1 {
+			return t
+		}
+
+		// Group consecutive entries into the nodes of the next level.
+		parents := make([]entry, 0, (len(level)+nodeSize-1)/nodeSize)
+		for i := 0; i < len(level); i += nodeSize {
+			box := level[i].box
+			for _, e := range level[i+1 : min(i+nodeSize, len(level))] {
+				box = union(box, e.box)
+			}
+			parents = append(parents, entry{box, start + i})
+		}
+		level = parents
+	}
+}
+
+// FromPolygons builds a tree holding the bounding boxes of polys, using the
+// index of each polygon as its ID. Empty polygons are left out.
+func FromPolygons(polys []polyclip.Polygon, nodeSize int) *Tree {
+	items := make([]Item, 0, len(polys))
+	for i, p := range polys {
+		if p.NumVertices() > 0 {
+			items = append(items, Item{p.BoundingBox(), i})
+		}
+	}
+	return New(items, nodeSize)
+}
+
+// Len returns the number of items in the tree.
+func (t *Tree) Len() int {
+	return t.levelEnd[0]
+}
+
+// Bounds returns the bounding box of all items in the tree, which is the
+// zero Rectangle if there are none.
+func (t *Tree) Bounds() polyclip.Rectangle {
+	if t.Len() == 0 {
+		return polyclip.Rectangle{}
+	}
+	return t.boxes[len(t.boxes)-1]
+}
+
+// children returns the range of positions of the entries below the node at pos,
+// which must not be an item.
+func (t *Tree) children(pos int) (start, end int) {
+	level := sort.SearchInts(t.levelEnd, pos+1) // the level of the node
+	start = t.refs[pos]
+	return start, min(start+t.nodeSize, t.levelEnd[level-1])
+}
+
+func (t *Tree) isItem(pos int) bool {
+	return pos < t.levelEnd[0]
+}
+
+// Iterator goes through the results of a search, one at a time:
+//
+//	for it := tree.Search(r); it.Next(); {
+//		use(it.ID(), it.Box())
+//	}
+type Iterator struct {
+	t     *Tree
+	r     polyclip.Rectangle
+	stack []int
+	pos   int
+}
+
+// Search returns an iterator over the items whose boxes overlap r, in no
+// particular order. Boxes touching r count as overlapping.
+func (t *Tree) Search(r polyclip.Rectangle) *Iterator {
+	it := &Iterator{t: t, r: r, pos: -1}
+	if t.Len() > 0 {
+		it.stack = []int{len(t.boxes) - 1}
+	}
+	return it
+}
+
+// Next advances to the next result, and returns false if there is none.
+func (it *Iterator) Next() bool {
+	for len(it.stack) > 0 {
+		pos := it.stack[len(it.stack)-1]
+		it.stack = it.stack[:len(it.stack)-1]
+		if !it.t.boxes[pos].Overlaps(it.r) {
+			continue
+		}
+		if it.t.isItem(pos) {
+			it.pos = pos
+			return true
+		}
+		start, end := it.t.children(pos)
+		for i := end - 1; i >= start; i-- {
+			it.stack = append(it.stack, i)
+		}
+	}
+	return false
+}
+
+// ID returns the ID of the current item.
+func (it *Iterator) ID() int {
+	return it.t.refs[it.pos]
+}
+
+// Box returns the box of the current item.
+func (it *Iterator) Box() polyclip.Rectangle {
+	return it.t.boxes[it.pos]
+}
+
+// SearchAll returns the IDs of all items whose boxes overlap r.
+func (t *Tree) SearchAll(r polyclip.Rectangle) []int {
+	var ids []int
+	for it := t.Search(r); it.Next(); {
+		ids = append(ids, it.ID())
+	}
+	return ids
+}
+
+// Nearest calls fn with the items in order of increasing distance of their
+// boxes from p, until fn returns false or all items have been visited.
+// Boxes containing p have distance 0.
+//
+// To find the nearest polygons rather than boxes, keep going until the box
+// distance exceeds the smallest exact distance found so far.
+func (t *Tree) Nearest(p polyclip.Point, fn func(id int, dist float64) bool) {
+	if t.Len() == 0 {
+		return
+	}
+	q := &queue{{len(t.boxes) - 1, boxDistance(p, t.Bounds())}}
+	for q.Len() > 0 {
+		c := heap.Pop(q).(candidate)
+		if t.isItem(c.pos) {
+			if !fn(t.refs[c.pos], c.dist) {
+				return
+			}
+			continue
+		}
+		start, end := t.children(c.pos)
+		for i := start; i < end; i++ {
+			heap.Push(q, candidate{i, boxDistance(p, t.boxes[i])})
+		}
+	}
+}
+
+// NearestK returns the IDs of the k items whose boxes are nearest to p, nearest first.
+func (t *Tree) NearestK(p polyclip.Point, k int) []int {
+	var ids []int
+	if k <= 0 {
+		return ids
+	}
+	t.Nearest(p, func(id int, _ float64) bool {
+		ids = append(ids, id)
+		return len(ids) < k
+	})
+	return ids
+}
+
+// sortTileRecursive orders entries so that each run of nodeSize consecutive
+// entries is spatially compact: the entries are sorted into vertical slices by
+// the X coordinates of their centers, and each slice by the Y coordinates.
+func sortTileRecursive(entries []entry, nodeSize int) {
+	center := func(r polyclip.Rectangle, axis int) float64 {
+		if axis == 0 {
+			return r.Min.X + r.Max.X
+		}
+		return r.Min.Y + r.Max.Y
+	}
+	byAxis := func(es []entry, axis int) {
+		sort.Slice(es, func(i, j int) bool { return center(es[i].box, axis) < center(es[j].box, axis) })
+	}
+
+	nodes := (len(entries) + nodeSize - 1) / nodeSize
+	slices := int(math.Ceil(math.Sqrt(float64(nodes))))
+	sliceSize := slices * nodeSize
+	byAxis(entries, 0)
+	for i := 0; i < len(entries); i += sliceSize {
+		byAxis(entries[i:min(i+sliceSize, len(entries))], 1)
+	}
+}
+
+func union(r1, r2 polyclip.Rectangle) polyclip.Rectangle {
+	return polyclip.Rectangle{
+		Min: polyclip.Point{X: math.Min(r1.Min.X, r2.Min.X), Y: math.Min(r1.Min.Y, r2.Min.Y)},
+		Max: polyclip.Point{X: math.Max(r1.Max.X, r2.Max.X), Y: math.Max(r1.Max.Y, r2.Max.Y)},
+	}
+}
+
+// boxDistance returns the distance from p to the nearest point of r.
+func boxDistance(p polyclip.Point, r polyclip.Rectangle) float64 {
+	dx := math.Max(0, math.Max(r.Min.X-p.X, p.X-r.Max.X))
+	dy := math.Max(0, math.Max(r.Min.Y-p.Y, p.Y-r.Max.Y))
+	return math.Hypot(dx, dy)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+type candidate struct {
+	pos  int
+	dist float64
+}
+
+// queue is a priority queue of candidates, nearest first.
+type queue []candidate
+
+func (q queue) Len() int            { return len(q) }
+func (q queue) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q queue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(candidate)) }
+func (q *queue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,106 @@
+package rtree
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ctessum/polyclip-go"
+)
+
+func randomItems(n int, rnd *rand.Rand) []Item {
+	items := make([]Item, n)
+	for i := range items {
+		x, y := rnd.Float64()*1000, rnd.Float64()*1000
+		items[i] = Item{polyclip.Rectangle{Min: polyclip.Point{X: x, Y: y}, Max: polyclip.Point{X: x + rnd.Float64()*20, Y: y + rnd.Float64()*20}}, i}
+	}
+	return items
+}
+
+func TestSearch(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 5, 16, 17, 1000} {
+		items := randomItems(n, rnd)
+		tree := New(items, 0)
+		if tree.Len() != n {
+			t.Errorf("n=%d: expected Len %d, got %d", n, n, tree.Len())
+		}
+		for q := 0; q < 50; q++ {
+			x, y := rnd.Float64()*1000, rnd.Float64()*1000
+			r := polyclip.Rectangle{Min: polyclip.Point{X: x, Y: y}, Max: polyclip.Point{X: x + 50, Y: y + 50}}
+			var want []int
+			for _, it := range items {
+				if it.Box.Overlaps(r) {
+					want = append(want, it.ID)
+				}
+			}
+			got := tree.SearchAll(r)
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("n=%d, query %v: expected %v, got %v", n, r, want, got)
+			}
+		}
+	}
+}
+
+func TestIteratorStops(t *testing.T) {
+	tree := New(randomItems(100, rand.New(rand.NewSource(2))), 4)
+	it := tree.Search(tree.Bounds())
+	for i := 0; i < 3; i++ {
+		if !it.Next() {
+			t.Fatalf("expected result %d", i)
+		}
+		if !it.Box().Overlaps(tree.Bounds()) {
+			t.Errorf("unexpected box %v", it.Box())
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	items := randomItems(500, rnd)
+	tree := New(items, 8)
+	for q := 0; q < 20; q++ {
+		p := polyclip.Point{X: rnd.Float64() * 1000, Y: rnd.Float64() * 1000}
+		dists := make([]float64, len(items))
+		for i, it := range items {
+			dists[i] = boxDistance(p, it.Box)
+		}
+		sorted := append([]float64{}, dists...)
+		sort.Float64s(sorted)
+
+		got := tree.NearestK(p, 10)
+		if len(got) != 10 {
+			t.Fatalf("expected 10 results, got %v", got)
+		}
+		for i, id := range got {
+			if dists[id] != sorted[i] {
+				t.Errorf("query %v, result %d: expected distance %g, got %g", p, i, sorted[i], dists[id])
+			}
+		}
+	}
+	if ids := New(nil, 0).NearestK(polyclip.Point{}, 3); len(ids) != 0 {
+		t.Errorf("expected no results from empty tree, got %v", ids)
+	}
+}
+
+func TestFromPolygons(t *testing.T) {
+	polys := []polyclip.Polygon{
+		{{{0, 0}, {1, 0}, {1, 1}}},
+		{},
+		{{{5, 5}, {6, 5}, {6, 6}}},
+	}
+	tree := FromPolygons(polys, 0)
+	if tree.Len() != 2 {
+		t.Errorf("expected empty polygon to be left out, got %d items", tree.Len())
+	}
+	got := tree.SearchAll(polys[2].BoundingBox())
+	if !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("expected [2], got %v", got)
+	}
+	want := polyclip.Rectangle{Max: polyclip.Point{X: 6, Y: 6}}
+	if tree.Bounds() != want {
+		t.Errorf("expected bounds %v, got %v", want, tree.Bounds())
+	}
+}
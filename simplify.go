@@ -19,35 +19,88 @@
 
 package polyclip
 
-import "fmt"
-
 // Simplify removes self-intersections and degenerate (repeated)
 // edges from polygons.
-func (p Polygon) Simplify() Polygon {
+// Of the options, WithProvenance, WithConditioning, WithLinkTolerance,
+// WithStats, WithDiagnostics, WithTracer, WithRecovery and WithReproducer
+// apply; the others are ignored. All origins recorded by WithProvenance have Source
+// SUBJECT.
+func (p Polygon) Simplify(opts ...Option) (result Polygon) {
 	c := new(clipper)
-	var edges int
-	for _, cont := range p {
+	for _, opt := range opts {
+		opt(c)
+	}
+	defer c.recoverPanic(true, 0, p, nil, &result)
+	if c.diagnostics != nil {
+		*c.diagnostics = Diagnostics{}
+	}
+	if c.stats != nil {
+		*c.stats = Stats{}
+	}
+	start := c.stats.start()
+	p = c.condition(p)[0]
+	for j, cont := range p {
 		for i := range cont {
-			addProcessedSegment(&c.eventQueue, cont.segment(i), _SUBJECT)
-			edges++
+			addProcessedSegment(&c.eventQueue, cont.segment(i), _SUBJECT, j, i)
 		}
 	}
+	if c.stats != nil {
+		c.stats.InputSegments = len(c.eventQueue.elements) / 2
+	}
+	start = c.stats.lap(statSetup, start)
+
+	connector := connector{operation: UNION, stats: c.stats, tracer: c.tracer} // to connect the edge solutions
+	connector.epsilon, connector.repairGap = c.linkTolerance, c.linkTolerance
+	if c.provenance != nil {
+		connector.origins = make(map[segment][]EdgeOrigin)
+	}
+
+	c.traceOperands(p, nil)
+	endpoints := c.subdivide(c.processIntersectionSimplify, nil)
+	start = c.stats.lap(statSweep, start)
 
-	connector := connector{operation: UNION} // to connect the edge solutions
+	// Coinciding pieces of edges cancel out in pairs. They need not leave the
+	// sweep line one after the other, as pieces ending at the same point may
+	// leave in between.
+	repeats := make(map[segment]int)
+	for _, e := range endpoints {
+		repeats[segment{e.other.p, e.p}]++
+	}
+	for _, e := range endpoints {
+		if s := (segment{e.other.p, e.p}); repeats[s]%2 == 1 {
+			repeats[s] = 0
+			c.trace(TraceEvent{Kind: TRACE_EMITTED, Point: e.p}, e)
+			connector.addEdge(e)
+		}
+	}
+	result = c.finish(&connector)
+	c.revert(result)
+	c.stats.lap(statConnect, start)
+	return result
+}
 
+// subdivide sweeps through the queued segments from left to right, calling
+// intersect on every pair of segments that become neighbours in the sweep line.
+// If inserted is not nil, it is called whenever a segment is inserted into the
+// sweep line, along with the segment immediately below it (or nil).
+// It returns the right endpoints of all resulting segments, in the order in
+// which they left the sweep line.
+func (c *clipper) subdivide(intersect func(e1, e2 *endpoint) []*endpoint, inserted func(e, prev *endpoint)) []*endpoint {
 	// This is the sweepline. That is, we go through all the polygon edges
 	// by sweeping from left to right.
 	S := sweepline{}
 
-	endpoints := make([]*endpoint, 0, edges)
+	endpoints := make([]*endpoint, 0, len(c.eventQueue.elements)/2)
 
 	for !c.eventQueue.IsEmpty() {
 		var prev, next *endpoint
 		e := c.eventQueue.dequeue()
-		_DBG(func() { fmt.Printf("\nProcess event: (of %d)\n%v\n", len(c.eventQueue.elements)+1, *e) })
+		c.stats.event(len(c.eventQueue.elements))
+		c.trace(TraceEvent{Kind: TRACE_DEQUEUED, Point: e.p, Left: e.left}, e)
 
 		if e.left { // the line segment must be inserted into S
 			pos := S.insert(e)
+			c.stats.sweepLine(len(S))
 
 			prev = nil
 			if pos > 0 {
@@ -58,20 +111,19 @@ func (p Polygon) Simplify() Polygon {
 				next = S[pos+1]
 			}
 
-			_DBG(func() {
-				fmt.Println("Status line after insertion: ")
-				for _, e := range S {
-					fmt.Println(*e)
-				}
-			})
+			if inserted != nil {
+				inserted(e, prev)
+			}
+
+			c.traceInserted(e, prev, next)
 
 			// Process a possible intersection between "e" and its next neighbor in S
 			if next != nil {
-				c.processIntersectionSimplify(e, next)
+				intersect(e, next)
 			}
 			// Process a possible intersection between "e" and its previous neighbor in S
 			if prev != nil {
-				divided := c.processIntersectionSimplify(prev, e)
+				divided := intersect(prev, e)
 				// If [prev] was divided, the context (sweep line S) for [e] may have changed,
 				// altering what e.inout and e.inside should be. [e] must thus be reenqueued to
 				// recompute e.inout and e.inside.
@@ -81,6 +133,7 @@ func (p Polygon) Simplify() Polygon {
 				if len(divided) == 1 && divided[0] == prev {
 					S.remove(e)
 					c.eventQueue.enqueue(e)
+					c.stats.count(statReenqueued)
 				}
 			}
 		} else { // the line segment must be removed from S
@@ -113,28 +166,12 @@ func (p Polygon) Simplify() Polygon {
 			}
 
 			if next != nil && prev != nil {
-				c.processIntersectionSimplify(next, prev)
+				intersect(next, prev)
 			}
-
-			_DBG(func() { fmt.Print("Connector:\n", connector, "\n") })
 		}
-		_DBG(func() {
-			fmt.Println("Status line after processing intersections: ")
-			for _, e := range S {
-				fmt.Println(*e)
-			}
-		})
+		c.traceState(e.p, S, nil)
 	}
-
-	for i, e := range endpoints {
-		if i == 0 || i == len(endpoints)-1 {
-			connector.add(e.segment())
-		} else if !(e.p.Equals(endpoints[i+1].p) && e.other.p.Equals(endpoints[i+1].other.p)) &&
-			!(e.p.Equals(endpoints[i-1].p) && e.other.p.Equals(endpoints[i-1].other.p)) {
-			connector.add(e.segment())
-		}
-	}
-	return connector.toPolygon()
+	return endpoints
 }
 
 func (c *clipper) processIntersectionSimplify(e1, e2 *endpoint) []*endpoint {
@@ -148,7 +185,13 @@ func (c *clipper) processIntersectionSimplify(e1, e2 *endpoint) []*endpoint {
 	// otherwise has the tendency to corrupt the original polygons with new, almost-parallel segments.
 	ip1 = snap(ip1, e1.p, e2.p, e1.other.p, e2.other.p)
 
+	c.trace(TraceEvent{Kind: TRACE_INTERSECTION, Point: ip1, Count: numIntersections}, e1, e2)
 	if numIntersections == 1 {
+		if (ip1.Equals(e1.p) || ip1.Equals(e1.other.p)) && (ip1.Equals(e2.p) || ip1.Equals(e2.other.p)) {
+			c.stats.count(statTouch)
+		} else {
+			c.stats.count(statCrossing)
+		}
 		ep := make([]*endpoint, 0, 2)
 		if !ip1.Equals(e1.p) && !ip1.Equals(e1.other.p) {
 			// e2 divides e1.
@@ -162,13 +205,19 @@ func (c *clipper) processIntersectionSimplify(e1, e2 *endpoint) []*endpoint {
 	}
 
 	// The line segements overlap.
+	c.stats.count(statOverlap)
 	ip2 = snap(ip2, e1.p, e2.p, e1.other.p, e2.other.p)
+	// Divide each segment at the left end of the overlap, or where that is its
+	// own left end, at the right end. The part of a segment divided at the
+	// left end is divided at the right end once it meets the other again.
 	ep := make([]*endpoint, 0, 2)
-	if !ip1.Equals(e1.p) && !ip2.Equals(e1.other.p) {
-		ep = append(ep, c.divideSegment(e1, ip1))
-	}
-	if !ip1.Equals(e2.p) && !ip2.Equals(e2.other.p) {
-		ep = append(ep, c.divideSegment(e2, ip1))
+	for _, e := range []*endpoint{e1, e2} {
+		switch {
+		case !ip1.Equals(e.p):
+			ep = append(ep, c.divideSegment(e, ip1))
+		case !ip2.Equals(e.other.p):
+			ep = append(ep, c.divideSegment(e, ip2))
+		}
 	}
 	return ep
 }
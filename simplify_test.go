@@ -95,5 +95,35 @@ func TestSimplify(t *testing.T) {
 			poly:   polyclip.Polygon{{{1, 2}, {2, 2}, {2, 3}, {1, 2}, {2, 2}, {2, 3}}},
 			result: polyclip.Polygon{},
 		},
+		{
+			name:   "Spike overlapping itself from its left end",
+			poly:   polyclip.Polygon{{{20, 2}, {0, 2}, {1, 2}}},
+			result: polyclip.Polygon{},
+		},
+		{
+			name: "Spike along an edge",
+			poly: polyclip.Polygon{
+				{{0, 0}, {0, 2}, {10, 0}},
+				{{20, 2}, {0, 2}, {1, 2}},
+			},
+			result: polyclip.Polygon{{{0, 2}, {0, 0}, {10, 0}}},
+		},
+		{
+			name: "Triangle sharing the right end of an edge",
+			poly: polyclip.Polygon{
+				{{0, 0}, {20, 0}, {20, 2}, {0, 2}},
+				{{5, 0}, {20, 0}, {20, 1}},
+			},
+			result: polyclip.Polygon{{{0, 2}, {0, 0}, {5, 0}, {20, 1}, {20, 2}}},
+		},
+		{
+			// The pieces of the spike up to the crossing leave the sweep line
+			// with a piece of the crossing edge in between.
+			name: "Crossed spike",
+			poly: polyclip.Polygon{{{29.254902005195618, 0}, {0, 0.000278666615486145}, {0, 0},
+				{12.094125300645828, 17.219500556588173}, {0, 0}}},
+			result: polyclip.Polygon{{{0.000195720334995107, 0.00027866475115847356},
+				{0, 0.000278666615486145}, {0, 0}, {29.254902005195618, 0}}},
+		},
 	}.verify(t)
 }
@@ -0,0 +1,187 @@
+package polyclip
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Stats describes the work done by a Boolean operation or Simplify, to help
+// tune workloads. Collecting it costs a few counter updates per event and
+// three clock readings per operation.
+//
+// The String method makes *Stats an expvar.Var, so that totals accumulated
+// with Add can be published:
+//
+//	expvar.Publish("polyclip", &total)
+//
+// Stats is not safe for concurrent use; guard totals shared between
+// goroutines with a lock, for example by publishing an expvar.Func instead.
+type Stats struct {
+	InputSegments int // non-degenerate edges of the operands
+	Events        int // sweep events processed
+	Reenqueued    int // events put back into the queue to be processed again
+
+	MaxQueueLen     int // peak number of queued events
+	MaxSweepLineLen int // peak number of segments crossing the sweep line
+
+	Crossings            int // pairs of segments intersecting at a single point other than a shared endpoint
+	Touches              int // pairs of segments meeting only at a shared endpoint
+	Overlaps             int // pairs of collinear, overlapping segments
+	DroppedIntersections int // intersections ignored as invalid; see Diagnostics
+	Divisions            int // segments divided at an intersection
+	DroppedDivisions     int // divisions discarded as invalid; see Diagnostics
+
+	ChainsStarted  int // chains of result edges started by the connector
+	SegmentsLinked int // result edges appended to an existing chain
+	ChainsJoined   int // pairs of chains joined into one
+	ChainsClosed   int // chains closed into contours
+
+	SetupTime   time.Duration // to queue the input segments
+	SweepTime   time.Duration // to sweep through the events
+	ConnectTime time.Duration // to form the result from the connected chains
+}
+
+// WithStats makes Construct or Simplify fill s with statistics about the operation.
+func WithStats(s *Stats) Option {
+	return func(c *clipper) {
+		c.stats = s
+	}
+}
+
+// Add adds the counts and timings of o to s, and raises the peaks of s to those of o.
+func (s *Stats) Add(o *Stats) {
+	s.InputSegments += o.InputSegments
+	s.Events += o.Events
+	s.Reenqueued += o.Reenqueued
+	if o.MaxQueueLen > s.MaxQueueLen {
+		s.MaxQueueLen = o.MaxQueueLen
+	}
+	if o.MaxSweepLineLen > s.MaxSweepLineLen {
+		s.MaxSweepLineLen = o.MaxSweepLineLen
+	}
+	s.Crossings += o.Crossings
+	s.Touches += o.Touches
+	s.Overlaps += o.Overlaps
+	s.DroppedIntersections += o.DroppedIntersections
+	s.Divisions += o.Divisions
+	s.DroppedDivisions += o.DroppedDivisions
+	s.ChainsStarted += o.ChainsStarted
+	s.SegmentsLinked += o.SegmentsLinked
+	s.ChainsJoined += o.ChainsJoined
+	s.ChainsClosed += o.ChainsClosed
+	s.SetupTime += o.SetupTime
+	s.SweepTime += o.SweepTime
+	s.ConnectTime += o.ConnectTime
+}
+
+// String returns s as a JSON object, with the times in nanoseconds.
+func (s *Stats) String() string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// statCounter identifies one of the counters of Stats.
+type statCounter int
+
+const (
+	statReenqueued statCounter = iota
+	statCrossing
+	statTouch
+	statOverlap
+	statDroppedIntersection
+	statDivision
+	statDroppedDivision
+	statChainStarted
+	statSegmentLinked
+	statChainJoined
+	statChainClosed
+)
+
+// count increments a counter. Like the other recording methods, it does
+// nothing if s is nil, so that callers need not check whether statistics
+// were requested.
+func (s *Stats) count(k statCounter) {
+	if s == nil {
+		return
+	}
+	switch k {
+	case statReenqueued:
+		s.Reenqueued++
+	case statCrossing:
+		s.Crossings++
+	case statTouch:
+		s.Touches++
+	case statOverlap:
+		s.Overlaps++
+	case statDroppedIntersection:
+		s.DroppedIntersections++
+	case statDivision:
+		s.Divisions++
+	case statDroppedDivision:
+		s.DroppedDivisions++
+	case statChainStarted:
+		s.ChainsStarted++
+	case statSegmentLinked:
+		s.SegmentsLinked++
+	case statChainJoined:
+		s.ChainsJoined++
+	case statChainClosed:
+		s.ChainsClosed++
+	}
+}
+
+// event records the processing of an event, with queued events left.
+func (s *Stats) event(queued int) {
+	if s == nil {
+		return
+	}
+	s.Events++
+	if queued+1 > s.MaxQueueLen {
+		s.MaxQueueLen = queued + 1
+	}
+}
+
+// sweepLine records the number of segments crossing the sweep line.
+func (s *Stats) sweepLine(n int) {
+	if s != nil && n > s.MaxSweepLineLen {
+		s.MaxSweepLineLen = n
+	}
+}
+
+// statPhase identifies one of the timed phases of an operation.
+type statPhase int
+
+const (
+	statSetup statPhase = iota
+	statSweep
+	statConnect
+)
+
+// start returns the time a phase starts.
+func (s *Stats) start() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// lap adds the time since start to phase, and returns the current time, at
+// which the next phase starts.
+func (s *Stats) lap(phase statPhase, start time.Time) time.Time {
+	if s == nil {
+		return start
+	}
+	now := time.Now()
+	switch phase {
+	case statSetup:
+		s.SetupTime += now.Sub(start)
+	case statSweep:
+		s.SweepTime += now.Sub(start)
+	case statConnect:
+		s.ConnectTime += now.Sub(start)
+	}
+	return now
+}
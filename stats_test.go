@@ -0,0 +1,57 @@
+package polyclip
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+var _ expvar.Var = (*Stats)(nil)
+
+func TestWithStats(t *testing.T) {
+	subject := Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}}
+	clipping := Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}}}
+	var s Stats
+	result := subject.Construct(UNION, clipping, WithStats(&s))
+
+	verify(t, s.InputSegments == 8, "expected 8 input segments, got %d", s.InputSegments)
+	// Each of the 4 divisions adds a segment, so 12 segments make 24 events.
+	verify(t, s.Events == 24, "expected 24 events, got %d", s.Events)
+	verify(t, s.Crossings == 2, "expected 2 crossings, got %d", s.Crossings)
+	verify(t, s.Divisions == 4, "expected 4 divisions, got %d", s.Divisions)
+	verify(t, s.DroppedIntersections == 0 && s.DroppedDivisions == 0, "expected nothing dropped, got %+v", s)
+	verify(t, s.MaxQueueLen >= 16, "expected at least 16 queued events, got %d", s.MaxQueueLen)
+	verify(t, s.MaxSweepLineLen >= 2, "expected at least 2 segments in the sweep line, got %d", s.MaxSweepLineLen)
+	verify(t, s.ChainsClosed == len(result), "expected %d closed chains, got %d", len(result), s.ChainsClosed)
+	verify(t, s.ChainsStarted > 0 && s.SegmentsLinked > 0, "expected chain operations, got %+v", s)
+	verify(t, s.SetupTime >= 0 && s.SweepTime >= 0 && s.ConnectTime >= 0, "expected non-negative times, got %+v", s)
+
+	// The statistics of a previous operation are cleared.
+	subject.Construct(UNION, Polygon{{{5, 5}, {6, 5}, {6, 6}}}, WithStats(&s))
+	verify(t, s == Stats{}, "expected empty statistics for a trivial operation, got %+v", s)
+}
+
+func TestSimplifyWithStats(t *testing.T) {
+	bowtie := Polygon{{{0, 0}, {2, 2}, {2, 0}, {0, 2}}}
+	var s Stats
+	result := bowtie.Simplify(WithStats(&s))
+	verify(t, s.InputSegments == 4, "expected 4 input segments, got %d", s.InputSegments)
+	verify(t, s.Crossings == 1, "expected 1 crossing, got %d", s.Crossings)
+	verify(t, s.Divisions == 2, "expected 2 divisions, got %d", s.Divisions)
+	verify(t, s.ChainsClosed == len(result), "expected %d closed chains, got %d", len(result), s.ChainsClosed)
+}
+
+func TestStatsAdd(t *testing.T) {
+	total := Stats{Events: 3, MaxQueueLen: 10, MaxSweepLineLen: 2, SweepTime: 5}
+	total.Add(&Stats{Events: 4, MaxQueueLen: 6, MaxSweepLineLen: 7, SweepTime: 1})
+	want := Stats{Events: 7, MaxQueueLen: 10, MaxSweepLineLen: 7, SweepTime: 6}
+	verify(t, total == want, "expected %+v, got %+v", want, total)
+}
+
+func TestStatsString(t *testing.T) {
+	s := Stats{InputSegments: 8, Crossings: 2, SweepTime: 1500}
+	var m map[string]int64
+	err := json.Unmarshal([]byte(s.String()), &m)
+	verify(t, err == nil, "invalid JSON %q: %v", s.String(), err)
+	verify(t, m["InputSegments"] == 8 && m["Crossings"] == 2 && m["SweepTime"] == 1500, "unexpected values in %q", s.String())
+}
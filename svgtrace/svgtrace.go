@@ -0,0 +1,246 @@
+// Package svgtrace draws the steps of a Boolean operation, or of Simplify, as
+// SVG images, one frame for every event of the sweep, to help understand how a
+// result came about:
+//
+//	rec := svgtrace.New()
+//	result := subject.Construct(polyclip.UNION, clipping, polyclip.WithTracer(rec))
+//	err := rec.WriteDir("frames")
+//
+// Each frame shows the operands (the subject in blue, the clipping polygon in
+// red), the sweep line through the point of the event, the segments crossing
+// it numbered from bottom to top, the intersection points found while
+// processing the event (hollow if dropped), and the chains of result edges
+// connected so far (open ones in orange, closed ones in green).
+package svgtrace
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ctessum/polyclip-go"
+)
+
+// DefaultWidth is the width of the frames in pixels, unless set otherwise.
+const DefaultWidth = 800
+
+const margin = 20 // pixels around the drawing
+
+// Frame is the state of the sweep after an event was processed, along with
+// the events traced while processing it.
+type Frame struct {
+	State  polyclip.TraceState
+	Events []polyclip.TraceEvent
+}
+
+// Recorder is a polyclip.StateTracer keeping the frames of an operation.
+type Recorder struct {
+	// Width is the width of the frames in pixels; their height follows from
+	// the shape of the operands.
+	Width int
+
+	subject, clipping polyclip.Polygon
+	frames            []Frame
+	pending           []polyclip.TraceEvent
+}
+
+// New returns an empty Recorder.
+func New() *Recorder {
+	return &Recorder{Width: DefaultWidth}
+}
+
+// TraceOperands starts recording a new operation on the given operands.
+func (r *Recorder) TraceOperands(subject, clipping polyclip.Polygon) {
+	r.subject, r.clipping = subject.Clone(), clipping.Clone()
+	r.frames, r.pending = nil, nil
+}
+
+// Trace keeps e for the frame of the event being processed.
+func (r *Recorder) Trace(e polyclip.TraceEvent) {
+	r.pending = append(r.pending, e)
+}
+
+// TraceState adds a frame.
+func (r *Recorder) TraceState(s polyclip.TraceState) {
+	r.frames = append(r.frames, Frame{State: s, Events: r.pending})
+	r.pending = nil
+}
+
+// Frames returns the frames recorded so far.
+func (r *Recorder) Frames() []Frame {
+	return r.frames
+}
+
+// WriteDir writes every frame to a file frame-NNNN.svg in dir, numbered from
+// 1, creating dir if needed.
+func (r *Recorder) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for i := range r.frames {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("frame-%04d.svg", i+1)))
+		if err != nil {
+			return err
+		}
+		err = r.WriteFrame(f, i)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFrame writes frame i as an SVG image to w.
+func (r *Recorder) WriteFrame(w io.Writer, i int) error {
+	if i < 0 || i >= len(r.frames) {
+		return fmt.Errorf("svgtrace: frame %d out of range [0, %d)", i, len(r.frames))
+	}
+	f := r.frames[i]
+	v := r.view(f)
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", v.width, v.height, v.width, v.height)
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+
+	// Operands
+	v.polygon(b, r.subject, `fill="blue" fill-opacity="0.1" stroke="blue"`)
+	v.polygon(b, r.clipping, `fill="red" fill-opacity="0.1" stroke="red"`)
+
+	// Result chains
+	for _, c := range f.State.ClosedChains {
+		v.polygon(b, polyclip.Polygon{c}, `fill="green" fill-opacity="0.2" stroke="green" stroke-width="3"`)
+	}
+	for _, c := range f.State.OpenChains {
+		fmt.Fprintf(b, `<polyline points="%s" fill="none" stroke="orange" stroke-width="3"/>`+"\n", v.points(c))
+	}
+
+	// Sweep line and the segments crossing it
+	x, y := v.pt(f.State.Point)
+	fmt.Fprintf(b, `<line x1="%.2f" y1="0" x2="%.2f" y2="%d" stroke="gray" stroke-dasharray="4 4"/>`+"\n", x, x, v.height)
+	for j, s := range f.State.SweepLine {
+		x1, y1 := v.pt(s.Left)
+		x2, y2 := v.pt(s.Right)
+		fmt.Fprintf(b, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="black" stroke-width="2"/>`+"\n", x1, y1, x2, y2)
+		lx, ly := v.pt(atX(s, f.State.Point.X))
+		fmt.Fprintf(b, `<text x="%.2f" y="%.2f" font-size="12" fill="black">%d</text>`+"\n", lx+4, ly-4, j)
+	}
+	fmt.Fprintf(b, `<circle cx="%.2f" cy="%.2f" r="4" fill="black"/>`+"\n", x, y)
+
+	// Intersections found
+	for _, e := range f.Events {
+		if e.Kind != polyclip.TRACE_INTERSECTION {
+			continue
+		}
+		ix, iy := v.pt(e.Point)
+		if e.Dropped {
+			fmt.Fprintf(b, `<circle cx="%.2f" cy="%.2f" r="5" fill="none" stroke="magenta" stroke-width="2"/>`+"\n", ix, iy)
+		} else {
+			fmt.Fprintf(b, `<circle cx="%.2f" cy="%.2f" r="5" fill="magenta"/>`+"\n", ix, iy)
+		}
+	}
+
+	fmt.Fprintf(b, `<text x="4" y="14" font-size="12" fill="black">`)
+	xml.EscapeText(b, []byte(fmt.Sprintf("%d/%d: %s", i+1, len(r.frames), describe(f))))
+	fmt.Fprintf(b, "</text>\n</svg>\n")
+	return b.Flush()
+}
+
+// describe summarizes the event of f.
+func describe(f Frame) string {
+	for _, e := range f.Events {
+		if e.Kind == polyclip.TRACE_DEQUEUED && len(e.Segments) > 0 {
+			side := "right"
+			if e.Left {
+				side = "left"
+			}
+			return fmt.Sprintf("%s endpoint (%v, %v) of %v", side, e.Point.X, e.Point.Y, e.Segments[0])
+		}
+	}
+	return fmt.Sprintf("event at (%v, %v)", f.State.Point.X, f.State.Point.Y)
+}
+
+// atX returns the point of s at x, or its middle if s is vertical.
+func atX(s polyclip.TraceSegment, x float64) polyclip.Point {
+	if s.Left.X == s.Right.X {
+		return polyclip.Point{X: s.Left.X, Y: (s.Left.Y + s.Right.Y) / 2}
+	}
+	t := math.Max(0, math.Min(1, (x-s.Left.X)/(s.Right.X-s.Left.X)))
+	return polyclip.Point{X: x, Y: s.Left.Y + t*(s.Right.Y-s.Left.Y)}
+}
+
+// view maps points to pixels, with the Y axis pointing up.
+type view struct {
+	min, max      polyclip.Point
+	scale         float64
+	width, height int
+}
+
+// view returns the view of frame f, showing the operands and all of its points.
+func (r *Recorder) view(f Frame) view {
+	v := view{
+		min: polyclip.Point{X: math.Inf(1), Y: math.Inf(1)},
+		max: polyclip.Point{X: math.Inf(-1), Y: math.Inf(-1)},
+	}
+	add := func(p polyclip.Point) {
+		v.min.X, v.min.Y = math.Min(v.min.X, p.X), math.Min(v.min.Y, p.Y)
+		v.max.X, v.max.Y = math.Max(v.max.X, p.X), math.Max(v.max.Y, p.Y)
+	}
+	for _, p := range []polyclip.Polygon{r.subject, r.clipping} {
+		for _, c := range p {
+			for _, pt := range c {
+				add(pt)
+			}
+		}
+	}
+	add(f.State.Point)
+	for _, e := range f.Events {
+		add(e.Point)
+	}
+
+	width := r.Width
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	size := math.Max(v.max.X-v.min.X, v.max.Y-v.min.Y)
+	v.scale = 1
+	if size > 0 {
+		v.scale = float64(width-2*margin) / size
+	}
+	v.width = width
+	v.height = int(math.Ceil((v.max.Y-v.min.Y)*v.scale)) + 2*margin
+	return v
+}
+
+func (v view) pt(p polyclip.Point) (x, y float64) {
+	return margin + (p.X-v.min.X)*v.scale, margin + (v.max.Y-p.Y)*v.scale
+}
+
+func (v view) points(c []polyclip.Point) string {
+	s := make([]string, len(c))
+	for i, p := range c {
+		x, y := v.pt(p)
+		s[i] = fmt.Sprintf("%.2f,%.2f", x, y)
+	}
+	return strings.Join(s, " ")
+}
+
+// polygon draws p as a single path, so that holes are left out.
+func (v view) polygon(w io.Writer, p polyclip.Polygon, attrs string) {
+	var d strings.Builder
+	for _, c := range p {
+		if len(c) > 0 {
+			fmt.Fprintf(&d, "M%sZ", v.points(c))
+		}
+	}
+	if d.Len() > 0 {
+		fmt.Fprintf(w, `<path d="%s" fill-rule="evenodd" %s/>`+"\n", d.String(), attrs)
+	}
+}
@@ -0,0 +1,121 @@
+package svgtrace
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ctessum/polyclip-go"
+)
+
+var (
+	subject  = polyclip.Polygon{{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}}}
+	clipping = polyclip.Polygon{{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 3}, {X: 1, Y: 3}}}
+)
+
+// wellFormed returns an error if svg is not well-formed XML.
+func wellFormed(svg []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(svg))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	rec := New()
+	var stats polyclip.Stats
+	subject.Construct(polyclip.UNION, clipping, polyclip.WithTracer(rec), polyclip.WithStats(&stats))
+
+	frames := rec.Frames()
+	if len(frames) != stats.Events {
+		t.Fatalf("expected %d frames, one per event, got %d", stats.Events, len(frames))
+	}
+	crossings := map[polyclip.Point]bool{}
+	var maxLine int
+	for _, f := range frames {
+		if len(f.Events) == 0 || f.Events[0].Kind != polyclip.TRACE_DEQUEUED {
+			t.Errorf("expected each frame to start with a dequeued event, got %v", f.Events)
+		}
+		for _, e := range f.Events {
+			if e.Kind == polyclip.TRACE_INTERSECTION && !e.Dropped {
+				crossings[e.Point] = true
+			}
+		}
+		if len(f.State.SweepLine) > maxLine {
+			maxLine = len(f.State.SweepLine)
+		}
+	}
+	for _, p := range []polyclip.Point{{X: 2, Y: 1}, {X: 1, Y: 2}} {
+		if !crossings[p] {
+			t.Errorf("expected an intersection at %v, got %v", p, crossings)
+		}
+	}
+	if maxLine != stats.MaxSweepLineLen {
+		t.Errorf("expected at most %d segments in the sweep line, got %d", stats.MaxSweepLineLen, maxLine)
+	}
+	last := frames[len(frames)-1].State
+	if len(last.ClosedChains) != 1 || len(last.OpenChains) != 0 || len(last.SweepLine) != 0 {
+		t.Errorf("expected a single closed chain at the end, got %+v", last)
+	}
+
+	for i := range frames {
+		var buf bytes.Buffer
+		if err := rec.WriteFrame(&buf, i); err != nil {
+			t.Fatal(err)
+		}
+		if err := wellFormed(buf.Bytes()); err != nil {
+			t.Errorf("frame %d: %v\n%s", i, err, buf.String())
+		}
+		if !strings.Contains(buf.String(), `stroke-dasharray`) {
+			t.Errorf("frame %d: expected a sweep line", i)
+		}
+	}
+	if err := rec.WriteFrame(io.Discard, len(frames)); err == nil {
+		t.Error("expected an error for a frame out of range")
+	}
+}
+
+func TestRecorderSimplify(t *testing.T) {
+	rec := New()
+	bowtie := polyclip.Polygon{{{X: 0, Y: 0}, {X: 2, Y: 2}, {X: 2, Y: 0}, {X: 0, Y: 2}}}
+	bowtie.Simplify(polyclip.WithTracer(rec))
+	if len(rec.Frames()) == 0 {
+		t.Fatal("expected frames")
+	}
+	var buf bytes.Buffer
+	if err := rec.WriteFrame(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := wellFormed(buf.Bytes()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWriteDir(t *testing.T) {
+	rec := New()
+	subject.Construct(polyclip.INTERSECTION, clipping, polyclip.WithTracer(rec))
+	dir := filepath.Join(t.TempDir(), "frames")
+	if err := rec.WriteDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(rec.Frames()) {
+		t.Errorf("expected %d files, got %d", len(rec.Frames()), len(files))
+	}
+	if len(files) > 0 && files[0].Name() != "frame-0001.svg" {
+		t.Errorf("expected frame-0001.svg first, got %s", files[0].Name())
+	}
+}
@@ -67,11 +67,19 @@ func segmentCompare(e1, e2 *endpoint) bool {
 		if e1.p.Equals(e2.p) {
 			return e1.below(e2.other.p)
 		}
-		// Different points
+		// Different points. Where the left endpoint of the segment inserted
+		// later lies on the other one, its right endpoint, which cannot, tells
+		// on which side it lies.
 		if endpointLess(e1, e2) { // has the line segment associated to e1 been inserted into S after the line segment associated to e2 ?
+			if signedArea(e2.p, e2.other.p, e1.p) == 0 {
+				return e2.above(e1.other.p)
+			}
 			return e2.above(e1.p)
 		}
 		// The line segment associated to e2 has been inserted into S after the line segment associated to e1
+		if signedArea(e1.p, e1.other.p, e2.p) == 0 {
+			return e1.below(e2.other.p)
+		}
 		return e1.below(e2.p)
 	// Segments are collinear. Just a consistent criterion is used
 	case e1.p.Equals(e2.p):
@@ -72,12 +72,7 @@ func DrawPolygons(mul float64, polys []polyclip.Polygon) *image.NRGBA {
 			min.Y = r0.Min.Y
 		}
 
-		for _, c := range polygon {
-			for j, p := range c {
-				c[j].X = (p.X - min.X) * mul
-				c[j].Y = (p.Y - min.Y) * mul
-			}
-		}
+		polygon.Transform(polyclip.Translate(-min.X, -min.Y).Compose(polyclip.Scale(mul, mul)))
 		r := safebbox(polygon)
 
 		img2 := image.NewNRGBA(img.Bounds().Add(translate).Union(image.Rect(int(r.Min.X), int(r.Min.Y), int(r.Max.X), int(r.Max.Y))))
@@ -0,0 +1,163 @@
+package polyclip
+
+import (
+	"math"
+	"sort"
+)
+
+// TileIndex identifies a cell of a regular grid by its column and row, counted
+// from the grid origin in the direction of increasing X and Y.
+type TileIndex struct {
+	Col, Row int
+}
+
+// Tile splits p along a regular grid of cells of size cellW by cellH, with the
+// corner of cell {0, 0} at origin. Each tile is the part of p within its cell,
+// grown by buffer on all sides, so that with a positive buffer neighbouring
+// tiles overlap. Only non-empty tiles are returned.
+//
+// Rather than clipping p to every cell, Tile sweeps its contours once across
+// the columns, cutting every edge where it crosses the border of a column, and
+// then the parts within each column once across the rows. Cutting takes time
+// O(n + k) for n edges crossing k borders. The parts within each tile are then
+// connected as in ClipToRect, taking time O(e log e + e·m) for e edges in the
+// tile with at most m chains open at once. Both cellW and cellH must be positive.
+func (p Polygon) Tile(origin Point, cellW, cellH float64, buffer float64) map[TileIndex]Polygon {
+	result := make(map[TileIndex]Polygon)
+	if p.NumVertices() == 0 {
+		return result
+	}
+	cols := newSlabs(0, origin.X, cellW, buffer)
+	rows := newSlabs(1, origin.Y, cellH, buffer)
+	bb := p.BoundingBox()
+	cols.span(bb.Min.X, bb.Max.X)
+	rows.span(bb.Min.Y, bb.Max.Y)
+
+	for col, column := range cols.split(p) {
+		for row, tile := range rows.split(column) {
+			r := Rectangle{
+				Min: Point{cols.lo(col), rows.lo(row)},
+				Max: Point{cols.hi(col), rows.hi(row)},
+			}
+			if part := connectClipped(tile, r); len(part) > 0 {
+				result[TileIndex{col, row}] = part
+			}
+		}
+	}
+	return result
+}
+
+// slabs divides the plane along an axis (0 for X, 1 for Y) into slabs, the
+// columns or rows of a grid, grown by buffer on either side: slab i extends
+// from origin+i*size-buffer to origin+(i+1)*size+buffer.
+type slabs struct {
+	axis                 int
+	origin, size, buffer float64
+	first, last          int       // the slabs to split into
+	lines                []float64 // the borders of those slabs, sorted
+}
+
+func newSlabs(axis int, origin, size, buffer float64) *slabs {
+	return &slabs{axis: axis, origin: origin, size: size, buffer: buffer}
+}
+
+func (s *slabs) lo(i int) float64 { return s.origin + float64(i)*s.size - s.buffer }
+func (s *slabs) hi(i int) float64 { return s.origin + float64(i+1)*s.size + s.buffer }
+
+// span limits the slabs to those overlapping the range from min to max.
+func (s *slabs) span(min, max float64) {
+	s.first = int(math.Floor((min-s.buffer-s.origin)/s.size)) - 1
+	s.last = int(math.Floor((max+s.buffer-s.origin)/s.size)) + 1
+	for s.first < s.last && s.hi(s.first) < min {
+		s.first++
+	}
+	for s.last > s.first && s.lo(s.last) > max {
+		s.last--
+	}
+	s.lines = s.lines[:0]
+	for i := s.first; i <= s.last; i++ {
+		s.lines = append(s.lines, s.lo(i), s.hi(i))
+	}
+	sort.Float64s(s.lines)
+}
+
+// containing returns the range of slabs containing the coordinate v.
+func (s *slabs) containing(v float64) (int, int) {
+	first := int(math.Floor((v-s.buffer-s.origin)/s.size)) - 1
+	last := int(math.Floor((v+s.buffer-s.origin)/s.size)) + 1
+	if first < s.first {
+		first = s.first
+	}
+	if last > s.last {
+		last = s.last
+	}
+	for first <= last && s.hi(first) < v {
+		first++
+	}
+	for last >= first && s.lo(last) > v {
+		last--
+	}
+	return first, last
+}
+
+// split cuts every edge of p where it crosses a slab border, and returns for
+// each slab the contours made up of the pieces within it, in the order of the
+// contours of p. Where a contour leaves a slab and comes back, it does so
+// across the same border, so that joining the pieces gives the contour clipped
+// to the slab, running along the border in between, as Sutherland–Hodgman
+// clipping does.
+func (s *slabs) split(p Polygon) map[int][]Contour {
+	result := make(map[int][]Contour)
+	for _, c := range p {
+		parts := make(map[int]Contour)
+		var order []int
+		add := func(a, b Point) {
+			first, last := s.containing((coord(a, s.axis) + coord(b, s.axis)) / 2)
+			for i := first; i <= last; i++ {
+				part, ok := parts[i]
+				if !ok {
+					order = append(order, i)
+				}
+				if len(part) == 0 || !part[len(part)-1].Equals(a) {
+					part.Add(a)
+				}
+				part.Add(b)
+				parts[i] = part
+			}
+		}
+		for i := range c {
+			e := c.segment(i)
+			if e.start.Equals(e.end) {
+				continue
+			}
+			// The borders crossed, in order from start to end.
+			v0, v1 := coord(e.start, s.axis), coord(e.end, s.axis)
+			lo, hi := sort.SearchFloat64s(s.lines, math.Min(v0, v1)), sort.SearchFloat64s(s.lines, math.Max(v0, v1))
+			crossed := s.lines[lo:hi]
+			prev := e.start
+			for j := range crossed {
+				v := crossed[j]
+				if v1 < v0 {
+					v = crossed[len(crossed)-1-j]
+				}
+				if v == v0 || v == v1 {
+					continue
+				}
+				next := rectSide{axis: s.axis, value: v}.crossing(e.start, e.end)
+				add(prev, next)
+				prev = next
+			}
+			add(prev, e.end)
+		}
+		for _, i := range order {
+			part := parts[i]
+			if n := len(part); n > 1 && part[0].Equals(part[n-1]) {
+				part = part[:n-1]
+			}
+			if len(part) >= 3 {
+				result[i] = append(result[i], part)
+			}
+		}
+	}
+	return result
+}
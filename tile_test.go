@@ -0,0 +1,90 @@
+package polyclip
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestTile(t *testing.T) {
+	// An L shape with a hole, covering parts of the cells with columns -1 to 2
+	// and rows 0 to 2, but none of the cells {1, 1}, {2, 1}, {1, 2} and {2, 2}.
+	p := Polygon{
+		{{-5, 0}, {25, 0}, {25, 8}, {8, 8}, {8, 25}, {-5, 25}},
+		{{0, 12}, {5, 12}, {5, 18}, {0, 18}},
+	}
+	origin := Point{0, 0}
+	cell := func(col, row int, buffer float64) Polygon {
+		x, y := float64(col)*10, float64(row)*10
+		return Polygon{{{x - buffer, y - buffer}, {x + 10 + buffer, y - buffer}, {x + 10 + buffer, y + 10 + buffer}, {x - buffer, y + 10 + buffer}}}
+	}
+
+	cases := []struct {
+		buffer float64
+		want   []TileIndex
+	}{
+		{0, []TileIndex{{-1, 0}, {-1, 1}, {-1, 2}, {0, 0}, {0, 1}, {0, 2}, {1, 0}, {2, 0}}},
+		// The buffered cells in row -1 reach up into the polygon.
+		{1, []TileIndex{{-1, -1}, {-1, 0}, {-1, 1}, {-1, 2}, {0, -1}, {0, 0}, {0, 1}, {0, 2}, {1, -1}, {1, 0}, {2, -1}, {2, 0}}},
+	}
+	for _, c := range cases {
+		buffer := c.buffer
+		tiles := p.Tile(origin, 10, 10, buffer)
+		var got []TileIndex
+		for idx, tile := range tiles {
+			got = append(got, idx)
+			want := p.Construct(INTERSECTION, cell(idx.Col, idx.Row, buffer))
+			verify(t, circa(area(tile), area(want)), "buffer %g, tile %v: expected area %g, got %g (%v)", buffer, idx, area(want), area(tile), tile)
+		}
+		sort.Slice(got, func(i, j int) bool {
+			if got[i].Col != got[j].Col {
+				return got[i].Col < got[j].Col
+			}
+			return got[i].Row < got[j].Row
+		})
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("buffer %g: expected tiles %v, got %v", buffer, c.want, got)
+		}
+	}
+
+	var total float64
+	for _, tile := range p.Tile(origin, 10, 10, 0) {
+		total += area(tile)
+	}
+	verify(t, circa(total, area(p)), "expected tiles to add up to area %g, got %g", area(p), total)
+	verify(t, len(Polygon{}.Tile(origin, 10, 10, 0)) == 0, "expected no tiles for empty polygon")
+}
+
+func TestTileMatchesClipToRect(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	// A star-shaped contour around (cx, cy), crossing many cells.
+	star := func(cx, cy float64) Contour {
+		var c Contour
+		n := 5 + rnd.Intn(20)
+		for i := 0; i < n; i++ {
+			a := 2 * math.Pi * float64(i) / float64(n)
+			r := 1 + 25*rnd.Float64()
+			c.Add(Point{cx + r*math.Cos(a), cy + r*math.Sin(a)})
+		}
+		return c
+	}
+	origin := Point{0.5, -3}
+	for i := 0; i < 50; i++ {
+		p := Polygon{star(rnd.Float64()*20, rnd.Float64()*20)}
+		for _, buffer := range []float64{0, 0.75, 4} {
+			tiles := p.Tile(origin, 7, 5, buffer)
+			for col := -6; col <= 9; col++ {
+				for row := -8; row <= 12; row++ {
+					r := Rectangle{
+						Min: Point{origin.X + float64(col)*7 - buffer, origin.Y + float64(row)*5 - buffer},
+						Max: Point{origin.X + float64(col+1)*7 + buffer, origin.Y + float64(row+1)*5 + buffer},
+					}
+					want, got := area(p.ClipToRect(r)), area(tiles[TileIndex{col, row}])
+					verify(t, circa(got, want), "case %d, buffer %g, tile {%d %d}: expected area %g, got %g", i, buffer, col, row, want, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package polyclip
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Tracer receives events describing the progress of a Boolean operation or
+// of Simplify, for debugging. Points are in the coordinates used by the sweep,
+// which differ from those of the operands if they were conditioned; see
+// WithConditioning.
+type Tracer interface {
+	Trace(e TraceEvent)
+}
+
+// StateTracer is a Tracer that is also passed the state of the sweep, which
+// is costly to collect, to visualize the sweep step by step.
+type StateTracer interface {
+	Tracer
+
+	// TraceOperands is passed the operands before the sweep starts, in the
+	// coordinates used by it. The clipping polygon is nil for Simplify.
+	TraceOperands(subject, clipping Polygon)
+
+	// TraceState is passed the state after each event was processed.
+	TraceState(s TraceState)
+}
+
+// TraceState is the state of the sweep after an event was processed.
+type TraceState struct {
+	Point     Point          // the point of the event, through which the sweep line runs
+	SweepLine []TraceSegment // the segments crossing the sweep line, from bottom to top
+
+	// The chains of result edges connected so far; during Simplify the edges
+	// are only connected after the sweep.
+	OpenChains, ClosedChains [][]Point
+}
+
+// WithTracer makes Construct or Simplify pass events to t as they happen.
+func WithTracer(t Tracer) Option {
+	return func(c *clipper) {
+		c.tracer = t
+	}
+}
+
+// TraceKind identifies the kind of a TraceEvent.
+type TraceKind int
+
+const (
+	TRACE_DEQUEUED     TraceKind = iota // Point was taken from the event queue, as an endpoint of Segments[0]
+	TRACE_INSERTED                      // Segments[0] was inserted into the sweep line, between Below and Above
+	TRACE_INTERSECTION                  // Segments[0] and Segments[1] intersect in Count points, the first one being Point
+	TRACE_DIVIDED                       // Segments[0] was divided at Point, unless Dropped
+	TRACE_EMITTED                       // Segments[0] was passed to the connector as an edge of the result
+	TRACE_CLOSED                        // the connector closed the chain of Points into a contour
+)
+
+var traceKindNames = [...]string{"dequeued", "inserted", "intersection", "divided", "emitted", "closed"}
+
+func (k TraceKind) String() string {
+	if k < 0 || int(k) >= len(traceKindNames) {
+		return fmt.Sprintf("TraceKind(%d)", int(k))
+	}
+	return traceKindNames[k]
+}
+
+// MarshalText encodes k by its name.
+func (k TraceKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// TraceEvent describes a step of the sweep. Which fields are set depends on Kind.
+type TraceEvent struct {
+	Kind     TraceKind
+	Point    Point
+	Segments []TraceSegment `json:",omitempty"`
+	Below    *TraceSegment  `json:",omitempty"` // the neighbours of an inserted segment in the sweep line, if any
+	Above    *TraceSegment  `json:",omitempty"`
+	Count    int            `json:",omitempty"` // the number of intersection points: 1, or 2 for overlapping segments
+	Dropped  bool           `json:",omitempty"` // whether an intersection or division was dropped as invalid
+	Points   []Point        `json:",omitempty"` // the points of a closed chain
+	Left     bool           `json:",omitempty"` // whether a dequeued point is the left endpoint of its segment
+}
+
+// TraceSegment describes a segment in the sweep, with the flags computed for it.
+type TraceSegment struct {
+	Left, Right Point
+	Origin      EdgeOrigin
+	InOut       bool   // whether the segment is a transition from outside to inside its polygon, going upwards
+	Inside      bool   // whether the segment is inside the other polygon
+	EdgeType    string // normal, non-contributing, same-transition or different-transition
+}
+
+var edgeTypeNames = map[edgeType]string{
+	_EDGE_NORMAL:               "normal",
+	_EDGE_NON_CONTRIBUTING:     "non-contributing",
+	_EDGE_SAME_TRANSITION:      "same-transition",
+	_EDGE_DIFFERENT_TRANSITION: "different-transition",
+}
+
+// traceSegment describes the segment of e.
+func traceSegment(e *endpoint) TraceSegment {
+	l, r := e.leftRight()
+	return TraceSegment{
+		Left:     l,
+		Right:    r,
+		Origin:   EdgeOrigin{Source: Source(e.polygonType), Contour: e.contour, Edge: e.edge},
+		InOut:    e.inout,
+		Inside:   e.inside,
+		EdgeType: edgeTypeNames[e.edgeType],
+	}
+}
+
+// trace passes ev to the tracer, if any, adding descriptions of the segments of es.
+func (c *clipper) trace(ev TraceEvent, es ...*endpoint) {
+	if c.tracer == nil {
+		return
+	}
+	for _, e := range es {
+		ev.Segments = append(ev.Segments, traceSegment(e))
+	}
+	c.tracer.Trace(ev)
+}
+
+// traceOperands passes the operands to the tracer, if it is a StateTracer.
+func (c *clipper) traceOperands(subject, clipping Polygon) {
+	if st, ok := c.tracer.(StateTracer); ok {
+		st.TraceOperands(subject, clipping)
+	}
+}
+
+// traceState passes the state of the sweep after processing the event at p to
+// the tracer, if it is a StateTracer. conn holds the result edges, if any.
+func (c *clipper) traceState(p Point, S sweepline, conn *connector) {
+	st, ok := c.tracer.(StateTracer)
+	if !ok {
+		return
+	}
+	state := TraceState{Point: p}
+	for _, e := range S {
+		state.SweepLine = append(state.SweepLine, traceSegment(e))
+	}
+	if conn != nil {
+		for _, ch := range conn.openPolys {
+			state.OpenChains = append(state.OpenChains, append([]Point(nil), ch.points...))
+		}
+		for _, ch := range conn.closedPolys {
+			state.ClosedChains = append(state.ClosedChains, append([]Point(nil), ch.points...))
+		}
+	}
+	st.TraceState(state)
+}
+
+// traceInserted passes the insertion of e between prev and next to the tracer, if any.
+func (c *clipper) traceInserted(e, prev, next *endpoint) {
+	if c.tracer == nil {
+		return
+	}
+	ev := TraceEvent{Kind: TRACE_INSERTED, Point: e.p, Segments: []TraceSegment{traceSegment(e)}}
+	if prev != nil {
+		s := traceSegment(prev)
+		ev.Below = &s
+	}
+	if next != nil {
+		s := traceSegment(next)
+		ev.Above = &s
+	}
+	c.tracer.Trace(ev)
+}
+
+// JSONTracer writes trace events as JSON objects, one per line.
+type JSONTracer struct {
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONTracer returns a tracer writing to w.
+func NewJSONTracer(w io.Writer) *JSONTracer {
+	return &JSONTracer{enc: json.NewEncoder(w)}
+}
+
+// Trace writes e, unless writing failed before.
+func (t *JSONTracer) Trace(e TraceEvent) {
+	if t.err == nil {
+		t.err = t.enc.Encode(e)
+	}
+}
+
+// Err returns the first error writing the events, if any.
+func (t *JSONTracer) Err() error {
+	return t.err
+}
+
+// TextTracer writes trace events in a human-readable form, one per line:
+//
+//	dequeued left (2, 0) of subject 0:1 (2, 0)-(2, 2)
+//	inserted subject 0:1 (2, 0)-(2, 2) below clipping 0:0 (1, 1)-(3, 1)
+//	intersection at (2, 1) of subject 0:1 (2, 0)-(2, 2) and clipping 0:0 (1, 1)-(3, 1)
+//	divided at (2, 1) subject 0:1 (2, 0)-(2, 1)
+//
+// Segments are described by their origin, as source contour:edge, and their
+// left and right endpoints. Overlapping segments are reported as "overlap".
+type TextTracer struct {
+	w   io.Writer
+	err error
+}
+
+// NewTextTracer returns a tracer writing to w.
+func NewTextTracer(w io.Writer) *TextTracer {
+	return &TextTracer{w: w}
+}
+
+// Trace writes e, unless writing failed before.
+func (t *TextTracer) Trace(e TraceEvent) {
+	if t.err != nil {
+		return
+	}
+	var b strings.Builder
+	if e.Kind == TRACE_INTERSECTION && e.Count == 2 {
+		b.WriteString("overlap")
+	} else {
+		b.WriteString(e.Kind.String())
+	}
+	switch e.Kind {
+	case TRACE_DEQUEUED:
+		side := "right"
+		if e.Left {
+			side = "left"
+		}
+		fmt.Fprintf(&b, " %s %s of", side, formatPoint(e.Point))
+	case TRACE_INTERSECTION:
+		if e.Dropped {
+			b.WriteString(" dropped")
+		}
+		fmt.Fprintf(&b, " at %s of", formatPoint(e.Point))
+	case TRACE_DIVIDED:
+		if e.Dropped {
+			b.WriteString(" dropped")
+		}
+		fmt.Fprintf(&b, " at %s", formatPoint(e.Point))
+	case TRACE_CLOSED:
+		for _, p := range e.Points {
+			b.WriteString(" " + formatPoint(p))
+		}
+	}
+	for i, s := range e.Segments {
+		if i > 0 {
+			b.WriteString(" and")
+		}
+		b.WriteString(" " + s.String())
+	}
+	if e.Below != nil {
+		b.WriteString(" above " + e.Below.String())
+	}
+	if e.Above != nil {
+		b.WriteString(" below " + e.Above.String())
+	}
+	b.WriteByte('\n')
+	_, t.err = io.WriteString(t.w, b.String())
+}
+
+// Err returns the first error writing the events, if any.
+func (t *TextTracer) Err() error {
+	return t.err
+}
+
+// String describes s by its origin and endpoints.
+func (s TraceSegment) String() string {
+	src := "subject"
+	if s.Origin.Source == CLIPPING {
+		src = "clipping"
+	}
+	return fmt.Sprintf("%s %d:%d %s-%s", src, s.Origin.Contour, s.Origin.Edge, formatPoint(s.Left), formatPoint(s.Right))
+}
+
+func formatPoint(p Point) string {
+	return fmt.Sprintf("(%v, %v)", p.X, p.Y)
+}
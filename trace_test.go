@@ -0,0 +1,99 @@
+package polyclip
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// traceRecorder keeps the events it is passed.
+type traceRecorder []TraceEvent
+
+func (r *traceRecorder) Trace(e TraceEvent) { *r = append(*r, e) }
+
+func (r traceRecorder) count(kind TraceKind) int {
+	n := 0
+	for _, e := range r {
+		if e.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func TestWithTracer(t *testing.T) {
+	subject := Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}}
+	clipping := Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}}}
+	var r traceRecorder
+	var s Stats
+	result := subject.Construct(UNION, clipping, WithTracer(&r), WithStats(&s))
+
+	verify(t, len(r) > 0 && r[0].Kind == TRACE_DEQUEUED, "expected the trace to start with a dequeued event, got %v", r)
+	verify(t, r.count(TRACE_DEQUEUED) == s.Events, "expected %d dequeued events, got %d", s.Events, r.count(TRACE_DEQUEUED))
+	verify(t, r.count(TRACE_DIVIDED) == s.Divisions, "expected %d divided events, got %d", s.Divisions, r.count(TRACE_DIVIDED))
+	verify(t, r.count(TRACE_INSERTED) == s.Events/2, "expected %d inserted events, got %d", s.Events/2, r.count(TRACE_INSERTED))
+	verify(t, r.count(TRACE_EMITTED) == result.NumVertices(), "expected %d emitted edges, got %d", result.NumVertices(), r.count(TRACE_EMITTED))
+	verify(t, r.count(TRACE_CLOSED) == len(result), "expected %d closed chains, got %d", len(result), r.count(TRACE_CLOSED))
+
+	for _, e := range r {
+		switch e.Kind {
+		case TRACE_INTERSECTION:
+			verify(t, len(e.Segments) == 2 && e.Count > 0, "expected two intersecting segments, got %+v", e)
+			if e.Point == (Point{2, 1}) || e.Point == (Point{1, 2}) {
+				verify(t, !e.Dropped, "expected the crossing at %v to be applied", e.Point)
+			}
+		case TRACE_INSERTED, TRACE_DEQUEUED, TRACE_DIVIDED, TRACE_EMITTED:
+			verify(t, len(e.Segments) == 1, "expected one segment, got %+v", e)
+		case TRACE_CLOSED:
+			verify(t, len(e.Points) == len(result[0]), "expected the chain %v, got %v", result[0], e.Points)
+		}
+	}
+}
+
+func TestSimplifyWithTracer(t *testing.T) {
+	bowtie := Polygon{{{0, 0}, {2, 2}, {2, 0}, {0, 2}}}
+	var r traceRecorder
+	result := bowtie.Simplify(WithTracer(&r))
+	verify(t, r.count(TRACE_DIVIDED) == 2, "expected 2 divided events, got %d", r.count(TRACE_DIVIDED))
+	verify(t, r.count(TRACE_CLOSED) == len(result), "expected %d closed chains, got %d", len(result), r.count(TRACE_CLOSED))
+}
+
+func TestJSONTracer(t *testing.T) {
+	var buf bytes.Buffer
+	tr := NewJSONTracer(&buf)
+	subject := Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}}
+	subject.Construct(INTERSECTION, Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}}}, WithTracer(tr))
+	verify(t, tr.Err() == nil, "unexpected error %v", tr.Err())
+
+	kinds := map[string]int{}
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var e struct{ Kind string }
+		err := json.Unmarshal(sc.Bytes(), &e)
+		verify(t, err == nil, "invalid JSON line %q: %v", sc.Text(), err)
+		kinds[e.Kind]++
+	}
+	for _, k := range []string{"dequeued", "inserted", "intersection", "divided", "emitted", "closed"} {
+		verify(t, kinds[k] > 0, "expected %q events, got %v", k, kinds)
+	}
+}
+
+func TestTextTracer(t *testing.T) {
+	var buf bytes.Buffer
+	tr := NewTextTracer(&buf)
+	subject := Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}}
+	subject.Construct(UNION, Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}}}, WithTracer(tr))
+	verify(t, tr.Err() == nil, "unexpected error %v", tr.Err())
+
+	for _, line := range []string{
+		"dequeued left (2, 0) of subject 0:1 (2, 0)-(2, 2)",
+		"inserted subject 0:1 (2, 0)-(2, 2) below clipping 0:0 (1, 1)-(3, 1)",
+		"intersection at (2, 1) of subject 0:1 (2, 0)-(2, 2) and clipping 0:0 (1, 1)-(3, 1)",
+		"divided at (2, 1) subject 0:1 (2, 0)-(2, 1)",
+		"closed (1, 3) (1, 2) (0, 2) (0, 0) (2, 0) (2, 1) (3, 1) (3, 3)",
+	} {
+		verify(t, strings.Contains(buf.String(), line+"\n"), "expected the line %q in:\n%s", line, buf.String())
+	}
+}
@@ -0,0 +1,186 @@
+package polyclip
+
+import "math"
+
+// PointZM is a point carrying an elevation (Z) and a measure (M) value.
+type PointZM struct {
+	X, Y, Z, M float64
+}
+
+// XY returns p without its Z and M values.
+func (p PointZM) XY() Point {
+	return Point{p.X, p.Y}
+}
+
+// ContourZM is a contour of points with Z and M values.
+type ContourZM []PointZM
+
+// PolygonZM is a polygon of points with Z and M values.
+type PolygonZM []ContourZM
+
+// XY returns p without its Z and M values.
+func (p PolygonZM) XY() Polygon {
+	r := make(Polygon, len(p))
+	for i, c := range p {
+		r[i] = make(Contour, len(c))
+		for j, pt := range c {
+			r[i][j] = pt.XY()
+		}
+	}
+	return r
+}
+
+// MergePolicy decides the Z and M values of a result vertex where the operands
+// provide different ones, such as where edges of both polygons cross, or where
+// vertices of both coincide. Each value is merged separately.
+type MergePolicy int
+
+const (
+	PREFER_SUBJECT  MergePolicy = iota // values of the subject, if it has any at the vertex
+	PREFER_CLIPPING                    // values of the clipping polygon, if it has any at the vertex
+	AVERAGE                            // mean of all values
+	MINIMUM                            // smallest value
+	MAXIMUM                            // largest value
+)
+
+// Construct performs a Boolean operation like Polygon.Construct, carrying the
+// Z and M values of the operands over to the result. Vertices created on an
+// edge, for example where it is intersected, get values linearly interpolated
+// between those of the endpoints of the input edge it lies on, as recorded by
+// WithProvenance. Where several input edges or vertices meet at a result
+// vertex, their values are merged according to policy. A vertex on no recorded
+// edge and at no input vertex gets the values of the nearest input edges, so
+// that Z and M are NaN only where the input values are.
+func (p PolygonZM) Construct(operation Op, clipping PolygonZM, policy MergePolicy) PolygonZM {
+	var prov Provenance
+	result := p.XY().Construct(operation, clipping.XY(), WithProvenance(&prov))
+	operands := [2]PolygonZM{p, clipping}
+
+	// Input vertices by location, to catch those of the other operand
+	// coinciding with a vertex.
+	vertices := make(map[Point][]zmValue)
+	for s, op := range operands {
+		for _, c := range op {
+			for _, pt := range c {
+				vertices[pt.XY()] = append(vertices[pt.XY()], zmValue{Source(s), pt.Z, pt.M})
+			}
+		}
+	}
+
+	zm := make(PolygonZM, len(result))
+	for i, c := range result {
+		closed := len(prov[i]) == len(c)
+		zm[i] = make(ContourZM, len(c))
+		for j, pt := range c {
+			var values []zmValue
+			add := func(v zmValue) {
+				for _, w := range values {
+					if w == v {
+						return
+					}
+				}
+				values = append(values, v)
+			}
+			// The vertex lies on the origins of the edges before and after it.
+			for _, e := range []int{j - 1, j} {
+				if closed {
+					e = (e + len(c)) % len(c)
+				}
+				if e < 0 || e >= len(prov[i]) || prov[i][e].Contour < 0 {
+					continue
+				}
+				o := prov[i][e]
+				in := operands[o.Source][o.Contour]
+				add(interpolateZM(in[o.Edge], in[(o.Edge+1)%len(in)], pt, o.Source))
+			}
+			for _, v := range vertices[pt] {
+				add(v)
+			}
+			if len(values) == 0 {
+				values = nearestZM(operands, pt)
+			}
+			zm[i][j] = policy.merge(pt, values)
+		}
+	}
+	return zm
+}
+
+// zmValue holds the Z and M values an operand provides at a point.
+type zmValue struct {
+	source Source
+	z, m   float64
+}
+
+// interpolateZM returns the values at pt, which lies on the edge from a to b.
+func interpolateZM(a, b PointZM, pt Point, source Source) zmValue {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	t := 0.
+	if l2 := dx*dx + dy*dy; l2 > 0 {
+		t = math.Max(0, math.Min(1, ((pt.X-a.X)*dx+(pt.Y-a.Y)*dy)/l2))
+	}
+	return zmValue{source, a.Z + t*(b.Z-a.Z), a.M + t*(b.M-a.M)}
+}
+
+// nearestZM returns the values at the points of the edges of operands nearest
+// to pt, interpolated along those edges.
+func nearestZM(operands [2]PolygonZM, pt Point) []zmValue {
+	var values []zmValue
+	nearest := math.Inf(1)
+	for s, op := range operands {
+		for _, c := range op {
+			for k := range c {
+				a, b := c[k], c[(k+1)%len(c)]
+				d := distance(pt, segment{a.XY(), b.XY()}.closestPoint(pt))
+				if d > nearest {
+					continue
+				}
+				if d < nearest {
+					nearest, values = d, values[:0]
+				}
+				values = append(values, interpolateZM(a, b, pt, Source(s)))
+			}
+		}
+	}
+	return values
+}
+
+// merge returns pt with values merged from values. Without values, Z and M are NaN.
+func (policy MergePolicy) merge(pt Point, values []zmValue) PointZM {
+	r := PointZM{X: pt.X, Y: pt.Y, Z: math.NaN(), M: math.NaN()}
+	if policy == PREFER_SUBJECT || policy == PREFER_CLIPPING {
+		preferred := SUBJECT
+		if policy == PREFER_CLIPPING {
+			preferred = CLIPPING
+		}
+		var filtered []zmValue
+		for _, v := range values {
+			if v.source == preferred {
+				filtered = append(filtered, v)
+			}
+		}
+		if len(filtered) > 0 {
+			values = filtered
+		}
+		policy = AVERAGE
+	}
+	for i, v := range values {
+		if i == 0 {
+			r.Z, r.M = v.z, v.m
+			continue
+		}
+		switch policy {
+		case AVERAGE:
+			r.Z += v.z
+			r.M += v.m
+		case MINIMUM:
+			r.Z, r.M = math.Min(r.Z, v.z), math.Min(r.M, v.m)
+		case MAXIMUM:
+			r.Z, r.M = math.Max(r.Z, v.z), math.Max(r.M, v.m)
+		}
+	}
+	if policy == AVERAGE && len(values) > 1 {
+		r.Z /= float64(len(values))
+		r.M /= float64(len(values))
+	}
+	return r
+}
@@ -0,0 +1,79 @@
+package polyclip
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConstructZM(t *testing.T) {
+	// A square rising from Z=0 on the left to Z=4 on the right, with M running
+	// along its outline, and a flat square at Z=10, M=0.
+	subject := PolygonZM{{{0, 0, 0, 0}, {4, 0, 4, 1}, {4, 4, 4, 2}, {0, 4, 0, 3}}}
+	clipping := PolygonZM{{{2, 2, 10, 0}, {6, 2, 10, 0}, {6, 6, 10, 0}, {2, 6, 10, 0}}}
+
+	zAt := func(r PolygonZM, pt Point) (PointZM, bool) {
+		for _, c := range r {
+			for _, p := range c {
+				if p.XY().Equals(pt) {
+					return p, true
+				}
+			}
+		}
+		return PointZM{}, false
+	}
+
+	cases := []struct {
+		policy MergePolicy
+		z      float64 // at the intersection point (4, 2)
+	}{
+		{PREFER_SUBJECT, 4},
+		{PREFER_CLIPPING, 10},
+		{AVERAGE, 7},
+		{MINIMUM, 4},
+		{MAXIMUM, 10},
+	}
+	for _, c := range cases {
+		r := subject.Construct(INTERSECTION, clipping, c.policy)
+		p, ok := zAt(r, Point{4, 2})
+		verify(t, ok && p.Z == c.z, "policy %v: expected Z %g at {4 2}, got %v in %v", c.policy, c.z, p, r)
+	}
+
+	r := subject.Construct(DIFFERENCE, clipping, PREFER_SUBJECT)
+	for _, c := range r {
+		for _, p := range c {
+			if p.XY().Equals(Point{2, 2}) {
+				// Inside of the subject, so only the clipping polygon has values.
+				verify(t, p.Z == 10, "expected Z of the clipping polygon, got %v", p)
+				continue
+			}
+			verify(t, p.Z == p.X, "expected Z interpolated along the subject, got %v", p)
+		}
+	}
+	// The point (2, 4) lies halfway along the top edge, from M=2 to M=3.
+	p, ok := zAt(r, Point{2, 4})
+	verify(t, ok && p.M == 2.5, "expected M 2.5 at {2 4}, got %v", p)
+	// Input vertices keep their values.
+	p, ok = zAt(r, Point{4, 0})
+	verify(t, ok && p.Z == 4 && p.M == 1, "expected {4 0 4 1}, got %v", p)
+}
+
+func TestConstructZMNoValues(t *testing.T) {
+	r := PolygonZM{{{0, 0, 1, 1}, {1, 0, 1, 1}, {1, 1, 1, 1}}}.Construct(UNION, nil, AVERAGE)
+	verify(t, len(r) == 1 && len(r[0]) == 3, "expected the subject, got %v", r)
+	for _, p := range r[0] {
+		verify(t, p.Z == 1 && p.M == 1 && !math.IsNaN(p.Z), "expected values kept, got %v", p)
+	}
+}
+
+func TestNearestZM(t *testing.T) {
+	subject := PolygonZM{{{0, 0, 0, 0}, {4, 0, 4, 8}, {4, 4, 8, 8}, {0, 4, 4, 0}}}
+	clipping := PolygonZM{{{-2, 0, 1, 1}, {-2, 4, 1, 1}, {-3, 4, 1, 1}}}
+	operands := [2]PolygonZM{subject, clipping}
+	// {2 -1} lies nearest to the middle of the subject's first edge.
+	values := nearestZM(operands, Point{2, -1})
+	verify(t, len(values) == 1 && values[0] == zmValue{SUBJECT, 2, 4}, "expected {2 4} of the subject, got %v", values)
+	// {-1 2} is as near to the subject's last edge as to the clipping polygon's first.
+	values = nearestZM(operands, Point{-1, 2})
+	verify(t, len(values) == 2 && values[0] == zmValue{SUBJECT, 2, 0} && values[1] == zmValue{CLIPPING, 1, 1},
+		"expected {2 0} of the subject and {1 1} of the clipping polygon, got %v", values)
+}